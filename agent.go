@@ -41,7 +41,17 @@ type Agent struct {
 	getApiKey         func(provider string) (string, error)
 	thinkingBudgets   map[ThinkingLevel]int
 	sessionID         string
+	seed              *int
+	budget            *Budget
 	middlewares       []ToolMiddleware
+	inputGuards       []Guard
+	outputGuards      []Guard
+	toolResultProc    ToolResultProcessor
+	promptTemplate    *promptTemplate
+	repairToolArgs    bool
+	traceID           string
+	toolFilter        ToolFilter
+	requestLogger     RequestLogger
 
 	// State
 	messages         []AgentMessage
@@ -79,15 +89,32 @@ func NewAgent(opts ...AgentOption) *Agent {
 }
 
 // Subscribe registers a listener for agent events. Returns an unsubscribe function.
-func (a *Agent) Subscribe(fn func(Event)) func() {
+// By default every event is delivered synchronously; see WithEventFilter and
+// WithAsyncDelivery.
+//
+// Usage:
+//
+//	unsub := agent.Subscribe(logToolCalls,
+//	    agentcore.WithEventFilter("tool_exec_*"),
+//	    agentcore.WithAsyncDelivery(256, agentcore.OverflowDrop),
+//	)
+//	defer unsub()
+func (a *Agent) Subscribe(fn func(Event), opts ...SubscribeOption) func() {
+	var sub subscription
+	for _, opt := range opts {
+		opt(&sub)
+	}
+	deliver, stop := sub.listener(fn)
+
 	a.mu.Lock()
 	defer a.mu.Unlock()
-	a.listeners = append(a.listeners, fn)
+	a.listeners = append(a.listeners, deliver)
 	idx := len(a.listeners) - 1
 	return func() {
 		a.mu.Lock()
-		defer a.mu.Unlock()
 		a.listeners[idx] = nil
+		a.mu.Unlock()
+		stop()
 	}
 }
 
@@ -96,6 +123,16 @@ func (a *Agent) Prompt(input string) error {
 	return a.PromptMessages(UserMsg(input))
 }
 
+// PromptWithImages starts a new conversation turn with text and attached images.
+// Models without image support fail the turn with llm.ErrImagesUnsupported.
+func (a *Agent) PromptWithImages(input string, images ...ImageData) error {
+	msg := UserMsg(input)
+	for _, img := range images {
+		msg.Content = append(msg.Content, ImageBlock(img.Data, img.MimeType))
+	}
+	return a.PromptMessages(msg)
+}
+
 // PromptMessages starts a new conversation turn with arbitrary AgentMessages.
 func (a *Agent) PromptMessages(msgs ...AgentMessage) error {
 	a.mu.Lock()
@@ -107,6 +144,9 @@ func (a *Agent) PromptMessages(msgs ...AgentMessage) error {
 	a.lastError = ""
 
 	ctx, cancel := context.WithCancel(context.Background())
+	if a.traceID != "" {
+		ctx = WithTraceID(ctx, a.traceID)
+	}
 	a.cancel = cancel
 	a.done = make(chan struct{})
 
@@ -154,6 +194,9 @@ func (a *Agent) Continue() error {
 	a.lastError = ""
 
 	ctx, cancel := context.WithCancel(context.Background())
+	if a.traceID != "" {
+		ctx = WithTraceID(ctx, a.traceID)
+	}
 	a.cancel = cancel
 	a.done = make(chan struct{})
 
@@ -297,11 +340,21 @@ func (a *Agent) SetModel(m ChatModel) {
 	a.model = m
 }
 
-// SetSystemPrompt changes the system prompt. Takes effect on the next turn.
+// SetSystemPrompt changes the system prompt, replacing any template set with
+// WithSystemPromptTemplate. Takes effect on the next turn.
 func (a *Agent) SetSystemPrompt(s string) {
 	a.mu.Lock()
 	defer a.mu.Unlock()
 	a.systemPrompt = s
+	a.promptTemplate = nil
+}
+
+// renderSystemPrompt returns the loop's prompt renderer, nil without a template.
+func (a *Agent) renderSystemPrompt() func([]Tool) (string, error) {
+	if a.promptTemplate == nil {
+		return nil
+	}
+	return a.promptTemplate.render
 }
 
 // SetTools replaces the tool set. Takes effect on the next turn.
@@ -311,6 +364,51 @@ func (a *Agent) SetTools(tools ...Tool) {
 	a.tools = tools
 }
 
+// AddTool registers a tool, replacing any tool with the same name.
+// Takes effect on the next turn; a running loop keeps its tool set.
+func (a *Agent) AddTool(t Tool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	tools := make([]Tool, 0, len(a.tools)+1)
+	for _, existing := range a.tools {
+		if existing.Name() != t.Name() {
+			tools = append(tools, existing)
+		}
+	}
+	a.tools = append(tools, t)
+}
+
+// RemoveTool unregisters the named tool. Returns false if it was not present.
+// Takes effect on the next turn.
+func (a *Agent) RemoveTool(name string) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	tools := make([]Tool, 0, len(a.tools))
+	for _, t := range a.tools {
+		if t.Name() != name {
+			tools = append(tools, t)
+		}
+	}
+	removed := len(tools) < len(a.tools)
+	a.tools = tools
+	return removed
+}
+
+// HasTool reports whether a tool with the given name is registered.
+func (a *Agent) HasTool(name string) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return findTool(a.tools, name) != nil
+}
+
+// SetTraceID sets the trace ID stamped on events of subsequent runs, e.g. an
+// incoming request ID. Empty generates a new trace ID per run.
+func (a *Agent) SetTraceID(id string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.traceID = id
+}
+
 // SetThinkingLevel changes the reasoning depth. Takes effect on the next turn.
 func (a *Agent) SetThinkingLevel(level ThinkingLevel) {
 	a.mu.Lock()
@@ -376,6 +474,8 @@ func (a *Agent) buildConfig() LoopConfig {
 		GetApiKey:        a.getApiKey,
 		ThinkingBudgets:  a.thinkingBudgets,
 		SessionID:        a.sessionID,
+		Seed:             a.seed,
+		Budget:           a.budget,
 		GetSteeringMessages: func() []AgentMessage {
 			a.mu.Lock()
 			defer a.mu.Unlock()
@@ -386,7 +486,14 @@ func (a *Agent) buildConfig() LoopConfig {
 			defer a.mu.Unlock()
 			return dequeue(&a.followUpQ, a.followUpMode)
 		},
-		Middlewares: a.middlewares,
+		Middlewares:        a.middlewares,
+		InputGuards:        a.inputGuards,
+		OutputGuards:       a.outputGuards,
+		ProcessToolResult:  a.toolResultProc,
+		RenderSystemPrompt: a.renderSystemPrompt(),
+		RepairToolArgs:     a.repairToolArgs,
+		ToolFilter:         a.toolFilter,
+		LogRequest:         a.requestLogger,
 	}
 }
 
@@ -0,0 +1,152 @@
+// Package approval provides human-in-the-loop tool approval for agentcore.
+// Approvers produce an agentcore.PermissionFunc that blocks each tool call
+// until a human approves or denies it, via Go channels or HTTP. Policy denies
+// calls by tool name and argument rules before any human is asked.
+package approval
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/voocel/agentcore"
+)
+
+// ErrTimeout is returned when no decision arrives before the approval timeout.
+var ErrTimeout = errors.New("approval timed out")
+
+// Decision is a human verdict on a pending tool call.
+type Decision struct {
+	Approved bool   `json:"approved"`
+	Feedback string `json:"feedback,omitempty"` // sent to the model as the denial reason
+}
+
+// Request is a tool call awaiting a decision.
+type Request struct {
+	ID        string             `json:"id"`
+	Call      agentcore.ToolCall `json:"call"`
+	CreatedAt time.Time          `json:"created_at"`
+
+	reply chan Decision
+}
+
+// Respond delivers the decision. Only the first call has effect.
+func (r *Request) Respond(d Decision) {
+	select {
+	case r.reply <- d:
+	default:
+	}
+}
+
+// Approve is shorthand for Respond(Decision{Approved: true}).
+func (r *Request) Approve() { r.Respond(Decision{Approved: true}) }
+
+// Deny is shorthand for Respond(Decision{Approved: false, Feedback: reason}).
+func (r *Request) Deny(reason string) { r.Respond(Decision{Feedback: reason}) }
+
+func newRequest(call agentcore.ToolCall) *Request {
+	return &Request{
+		ID:        newID(),
+		Call:      call,
+		CreatedAt: time.Now(),
+		reply:     make(chan Decision, 1),
+	}
+}
+
+// await blocks until a decision, context cancellation, or timeout (0 = none).
+func await(ctx context.Context, req *Request, timeout time.Duration) error {
+	var timer <-chan time.Time
+	if timeout > 0 {
+		t := time.NewTimer(timeout)
+		defer t.Stop()
+		timer = t.C
+	}
+
+	select {
+	case d := <-req.reply:
+		return decisionErr(req.Call, d)
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer:
+		return fmt.Errorf("tool %q: %w", req.Call.Name, ErrTimeout)
+	}
+}
+
+// decisionErr converts a decision into a PermissionFunc result.
+func decisionErr(call agentcore.ToolCall, d Decision) error {
+	if d.Approved {
+		return nil
+	}
+	if d.Feedback != "" {
+		return fmt.Errorf("tool %q denied by user: %s", call.Name, d.Feedback)
+	}
+	return fmt.Errorf("tool %q denied by user", call.Name)
+}
+
+func newID() string {
+	var b [8]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// ---------------------------------------------------------------------------
+// Channel approver
+// ---------------------------------------------------------------------------
+
+// Channel delivers approval requests over a Go channel.
+// Consumers read Requests() and call Respond/Approve/Deny on each.
+//
+// Usage:
+//
+//	approver := approval.NewChannel(5 * time.Minute)
+//	agent := agentcore.NewAgent(agentcore.WithPermission(approver.Permission()))
+//	go func() {
+//	    for req := range approver.Requests() {
+//	        req.Approve()
+//	    }
+//	}()
+type Channel struct {
+	requests chan *Request
+	timeout  time.Duration
+}
+
+// NewChannel creates a channel approver. timeout 0 waits indefinitely
+// (bounded only by the agent's context).
+func NewChannel(timeout time.Duration) *Channel {
+	return &Channel{requests: make(chan *Request), timeout: timeout}
+}
+
+// Requests returns the channel of pending approval requests.
+func (c *Channel) Requests() <-chan *Request { return c.requests }
+
+// Permission returns a PermissionFunc that blocks until the request is answered.
+func (c *Channel) Permission() agentcore.PermissionFunc {
+	return func(ctx context.Context, call agentcore.ToolCall) error {
+		req := newRequest(call)
+
+		// The timeout covers delivery as well as the decision.
+		if c.timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, c.timeout)
+			defer cancel()
+		}
+
+		select {
+		case c.requests <- req:
+		case <-ctx.Done():
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return fmt.Errorf("tool %q: %w", call.Name, ErrTimeout)
+			}
+			return ctx.Err()
+		}
+
+		err := await(ctx, req, 0)
+		if errors.Is(err, context.DeadlineExceeded) {
+			return fmt.Errorf("tool %q: %w", call.Name, ErrTimeout)
+		}
+		return err
+	}
+}
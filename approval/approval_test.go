@@ -0,0 +1,186 @@
+package approval
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/voocel/agentcore"
+)
+
+var testCall = agentcore.ToolCall{ID: "1", Name: "bash", Args: json.RawMessage(`{"command":"ls"}`)}
+
+func TestChannelResolve(t *testing.T) {
+	tests := []struct {
+		name    string
+		respond func(*Request)
+		wantErr string // "" = approved
+	}{
+		{"approve", (*Request).Approve, ""},
+		{"deny", func(r *Request) { r.Deny("not now") }, "not now"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			approver := NewChannel(time.Second)
+			go func() {
+				req := <-approver.Requests()
+				if req.Call.Name != "bash" {
+					t.Errorf("request for %q, want bash", req.Call.Name)
+				}
+				tt.respond(req)
+			}()
+			err := approver.Permission()(context.Background(), testCall)
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("err = %v, want approval", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("err = %v, want denial with %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestChannelTimeout(t *testing.T) {
+	approver := NewChannel(20 * time.Millisecond)
+
+	// Nobody reads the request.
+	if err := approver.Permission()(context.Background(), testCall); !errors.Is(err, ErrTimeout) {
+		t.Fatalf("undelivered: err = %v, want ErrTimeout", err)
+	}
+
+	// Read but never answered.
+	go func() { <-approver.Requests() }()
+	if err := approver.Permission()(context.Background(), testCall); !errors.Is(err, ErrTimeout) {
+		t.Fatalf("unanswered: err = %v, want ErrTimeout", err)
+	}
+}
+
+func TestChannelCanceled(t *testing.T) {
+	approver := NewChannel(0)
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-approver.Requests()
+		cancel()
+	}()
+	if err := approver.Permission()(ctx, testCall); !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want context.Canceled", err)
+	}
+}
+
+// awaitPending polls until h has a pending request and returns it.
+func awaitPending(t *testing.T, h *HTTP) *Request {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if p := h.Pending(); len(p) > 0 {
+			return p[0]
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatal("no pending request")
+	return nil
+}
+
+func TestHTTPResolve(t *testing.T) {
+	approver := NewHTTP(time.Second)
+	srv := httptest.NewServer(approver.Handler())
+	defer srv.Close()
+
+	done := make(chan error, 1)
+	go func() { done <- approver.Permission()(context.Background(), testCall) }()
+	req := awaitPending(t, approver)
+
+	resp, err := http.Get(srv.URL + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var listed []Request
+	json.NewDecoder(resp.Body).Decode(&listed)
+	resp.Body.Close()
+	if len(listed) != 1 || listed[0].ID != req.ID || listed[0].Call.Name != "bash" {
+		t.Fatalf("GET / = %+v, want the pending bash call", listed)
+	}
+
+	resp, err = http.Post(srv.URL+"/"+req.ID, "application/json", strings.NewReader(`{"approved":false,"feedback":"too risky"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent {
+		t.Fatalf("POST status = %d, want 204", resp.StatusCode)
+	}
+	if err := <-done; err == nil || !strings.Contains(err.Error(), "too risky") {
+		t.Fatalf("err = %v, want the posted denial", err)
+	}
+	if len(approver.Pending()) != 0 {
+		t.Fatal("resolved request still pending")
+	}
+}
+
+func TestHTTPHandlerErrors(t *testing.T) {
+	approver := NewHTTP(time.Second)
+	srv := httptest.NewServer(approver.Handler())
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go approver.Permission()(ctx, testCall)
+	req := awaitPending(t, approver)
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		want   int
+	}{
+		{"unknown id", http.MethodPost, "/nope", `{"approved":true}`, http.StatusNotFound},
+		{"invalid body", http.MethodPost, "/" + req.ID, `{"approved":`, http.StatusBadRequest},
+		{"oversized body", http.MethodPost, "/" + req.ID, `{"feedback":"` + strings.Repeat("x", maxDecisionBytes) + `"}`, http.StatusRequestEntityTooLarge},
+		{"bad method", http.MethodDelete, "/" + req.ID, "", http.StatusMethodNotAllowed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, _ := http.NewRequest(tt.method, srv.URL+tt.path, strings.NewReader(tt.body))
+			resp, err := http.DefaultClient.Do(r)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != tt.want {
+				t.Fatalf("status = %d, want %d", resp.StatusCode, tt.want)
+			}
+		})
+	}
+	if len(approver.Pending()) != 1 {
+		t.Fatal("a rejected POST resolved the pending request")
+	}
+	if approver.Resolve("nope", Decision{Approved: true}) {
+		t.Fatal("Resolve of an unknown id reported success")
+	}
+}
+
+func TestHTTPTimeoutAndCancel(t *testing.T) {
+	approver := NewHTTP(20 * time.Millisecond)
+	if err := approver.Permission()(context.Background(), testCall); !errors.Is(err, ErrTimeout) {
+		t.Fatalf("err = %v, want ErrTimeout", err)
+	}
+
+	approver = NewHTTP(0)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- approver.Permission()(ctx, testCall) }()
+	req := awaitPending(t, approver)
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want context.Canceled", err)
+	}
+	if len(approver.Pending()) != 0 || approver.Resolve(req.ID, Decision{Approved: true}) {
+		t.Fatal("canceled request still pending")
+	}
+}
@@ -0,0 +1,125 @@
+package approval
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/voocel/agentcore"
+)
+
+// maxDecisionBytes caps the size of a POSTed Decision body.
+const maxDecisionBytes = 64 << 10
+
+// HTTP exposes pending approvals through an http.Handler so a web UI can
+// approve or deny tool calls.
+//
+// Routes (relative to where Handler is mounted):
+//
+//	GET  /        list pending requests as JSON
+//	POST /{id}    submit a Decision JSON body: {"approved": true, "feedback": "..."}
+//
+// Usage:
+//
+//	approver := approval.NewHTTP(10 * time.Minute)
+//	http.Handle("/approvals/", http.StripPrefix("/approvals", approver.Handler()))
+//	agent := agentcore.NewAgent(agentcore.WithPermission(approver.Permission()))
+type HTTP struct {
+	timeout time.Duration
+
+	mu      sync.Mutex
+	pending map[string]*Request
+}
+
+// NewHTTP creates an HTTP approver. timeout 0 waits indefinitely
+// (bounded only by the agent's context).
+func NewHTTP(timeout time.Duration) *HTTP {
+	return &HTTP{timeout: timeout, pending: make(map[string]*Request)}
+}
+
+// Permission returns a PermissionFunc that registers the call as pending and
+// blocks until a decision is POSTed, the context is canceled, or the timeout fires.
+func (h *HTTP) Permission() agentcore.PermissionFunc {
+	return func(ctx context.Context, call agentcore.ToolCall) error {
+		req := newRequest(call)
+
+		h.mu.Lock()
+		h.pending[req.ID] = req
+		h.mu.Unlock()
+
+		defer func() {
+			h.mu.Lock()
+			delete(h.pending, req.ID)
+			h.mu.Unlock()
+		}()
+
+		return await(ctx, req, h.timeout)
+	}
+}
+
+// Pending returns a snapshot of requests awaiting a decision, oldest first.
+func (h *HTTP) Pending() []*Request {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	out := make([]*Request, 0, len(h.pending))
+	for _, r := range h.pending {
+		out = append(out, r)
+	}
+	slices.SortFunc(out, func(a, b *Request) int { return a.CreatedAt.Compare(b.CreatedAt) })
+	return out
+}
+
+// Resolve delivers a decision for the given request ID.
+// Returns false if the request is unknown or already resolved.
+func (h *HTTP) Resolve(id string, d Decision) bool {
+	h.mu.Lock()
+	req, ok := h.pending[id]
+	if ok {
+		delete(h.pending, id)
+	}
+	h.mu.Unlock()
+	if !ok {
+		return false
+	}
+	req.Respond(d)
+	return true
+}
+
+// Handler returns the HTTP handler for listing and resolving approvals.
+func (h *HTTP) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := strings.Trim(r.URL.Path, "/")
+
+		switch {
+		case r.Method == http.MethodGet && id == "":
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(h.Pending())
+
+		case r.Method == http.MethodPost && id != "":
+			var d Decision
+			body := http.MaxBytesReader(w, r.Body, maxDecisionBytes)
+			if err := json.NewDecoder(body).Decode(&d); err != nil {
+				status := http.StatusBadRequest
+				var tooLarge *http.MaxBytesError
+				if errors.As(err, &tooLarge) {
+					status = http.StatusRequestEntityTooLarge
+				}
+				http.Error(w, "invalid decision body: "+err.Error(), status)
+				return
+			}
+			if !h.Resolve(id, d) {
+				http.Error(w, "approval request not found", http.StatusNotFound)
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+
+		default:
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+}
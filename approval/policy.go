@@ -0,0 +1,197 @@
+package approval
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/url"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/voocel/agentcore"
+)
+
+// ErrPolicyDenied is wrapped by every error a Policy returns.
+var ErrPolicyDenied = errors.New("denied by tool policy")
+
+// Constraint checks a tool call's decoded arguments. The returned error is
+// shown to the model as the denial reason.
+type Constraint func(args map[string]any) error
+
+// Policy allows or denies tool calls by name and by argument values, without
+// asking a human. Set Next to chain a human approver for calls the policy
+// lets through.
+//
+// Usage:
+//
+//	policy := &approval.Policy{
+//	    Deny: []string{"bash"},
+//	    Args: map[string][]approval.Constraint{
+//	        "read":  {approval.PathPrefix("path", "/data")},
+//	        "fetch": {approval.HostAllowlist("url", "api.example.com", "*.internal.example.com")},
+//	        "ls":    {approval.MaxNumber("depth", 2)},
+//	    },
+//	}
+//	agent := agentcore.NewAgent(agentcore.WithPermission(policy.Permission()))
+type Policy struct {
+	Allow []string                // if non-empty, only these tools may run
+	Deny  []string                // always denied, checked before Allow
+	Args  map[string][]Constraint // per-tool argument constraints
+	Next  agentcore.PermissionFunc
+}
+
+// Permission returns a PermissionFunc enforcing the policy.
+func (p *Policy) Permission() agentcore.PermissionFunc {
+	return func(ctx context.Context, call agentcore.ToolCall) error {
+		if err := p.Check(call); err != nil {
+			return err
+		}
+		if p.Next != nil {
+			return p.Next(ctx, call)
+		}
+		return nil
+	}
+}
+
+// Check evaluates the policy against a single call.
+func (p *Policy) Check(call agentcore.ToolCall) error {
+	if slices.Contains(p.Deny, call.Name) {
+		return fmt.Errorf("tool %q: %w", call.Name, ErrPolicyDenied)
+	}
+	if len(p.Allow) > 0 && !slices.Contains(p.Allow, call.Name) {
+		return fmt.Errorf("tool %q: %w: not in the allowed tool list", call.Name, ErrPolicyDenied)
+	}
+	constraints := p.Args[call.Name]
+	if len(constraints) == 0 {
+		return nil
+	}
+	var args map[string]any
+	if len(call.Args) > 0 {
+		if err := json.Unmarshal(call.Args, &args); err != nil {
+			return fmt.Errorf("tool %q: %w: arguments are not a JSON object", call.Name, ErrPolicyDenied)
+		}
+	}
+	for _, c := range constraints {
+		if err := c(args); err != nil {
+			return fmt.Errorf("tool %q: %w: %v", call.Name, ErrPolicyDenied, err)
+		}
+	}
+	return nil
+}
+
+// PathPrefix requires the string argument field to be an absolute path inside
+// one of dirs. Relative paths are denied, since the policy cannot know what
+// the tool resolves them against; use PathPrefixIn for tools with a working
+// directory. Paths are cleaned first, so "/data/../etc" does not match
+// "/data". Symlinks are not followed. A missing field passes.
+func PathPrefix(field string, dirs ...string) Constraint {
+	return PathPrefixIn("", field, dirs...)
+}
+
+// PathPrefixIn is PathPrefix for a tool that resolves relative paths against
+// workDir, such as tools.LsTool, FindTool, or GrepTool with the same WorkDir.
+// Relative paths and relative dirs are joined to workDir before matching.
+//
+// Usage:
+//
+//	approval.PathPrefixIn(ls.WorkDir, "path", "src", "docs")
+func PathPrefixIn(workDir, field string, dirs ...string) Constraint {
+	resolve := func(p string) (string, bool) {
+		if filepath.IsAbs(p) {
+			return filepath.Clean(p), true
+		}
+		if workDir == "" {
+			return "", false
+		}
+		abs, err := filepath.Abs(filepath.Join(workDir, p))
+		return abs, err == nil
+	}
+	return func(args map[string]any) error {
+		v, ok := args[field]
+		if !ok {
+			return nil
+		}
+		s, ok := v.(string)
+		if !ok {
+			return fmt.Errorf("%s must be a string", field)
+		}
+		path, ok := resolve(s)
+		if !ok {
+			return fmt.Errorf("%s %q must be an absolute path", field, s)
+		}
+		for _, dir := range dirs {
+			dir, ok := resolve(dir)
+			if !ok {
+				continue
+			}
+			if path == dir || strings.HasPrefix(path, strings.TrimSuffix(dir, string(filepath.Separator))+string(filepath.Separator)) {
+				return nil
+			}
+		}
+		return fmt.Errorf("%s %q is outside the allowed directories %v", field, s, dirs)
+	}
+}
+
+// HostAllowlist requires the URL argument field to target one of hosts.
+// A "*." prefix matches any subdomain. Ports are ignored. A missing field
+// passes.
+func HostAllowlist(field string, hosts ...string) Constraint {
+	return func(args map[string]any) error {
+		v, ok := args[field]
+		if !ok {
+			return nil
+		}
+		s, ok := v.(string)
+		if !ok {
+			return fmt.Errorf("%s must be a string", field)
+		}
+		u, err := url.Parse(s)
+		if err != nil || u.Hostname() == "" {
+			return fmt.Errorf("%s %q is not an absolute URL", field, s)
+		}
+		host := strings.ToLower(u.Hostname())
+		for _, h := range hosts {
+			h = strings.ToLower(h)
+			if host == h {
+				return nil
+			}
+			if suffix, ok := strings.CutPrefix(h, "*"); ok && strings.HasSuffix(host, suffix) {
+				return nil
+			}
+		}
+		return fmt.Errorf("host %q is not in the allowlist %v", host, hosts)
+	}
+}
+
+// MaxNumber requires the numeric argument field to be at most limit.
+// Numeric strings are accepted, since permission checks run before arguments
+// are coerced to the tool schema. A missing field passes.
+func MaxNumber(field string, limit float64) Constraint {
+	return func(args map[string]any) error {
+		v, ok := args[field]
+		if !ok {
+			return nil
+		}
+		var n float64
+		switch x := v.(type) {
+		case float64:
+			n = x
+		case string:
+			f, err := strconv.ParseFloat(strings.TrimSpace(x), 64)
+			if err != nil {
+				return fmt.Errorf("%s must be a number", field)
+			}
+			n = f
+		default:
+			return fmt.Errorf("%s must be a number", field)
+		}
+		if n > limit || math.IsNaN(n) {
+			return fmt.Errorf("%s %v exceeds the maximum %v", field, n, limit)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,43 @@
+package approval
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestPathPrefix(t *testing.T) {
+	work := t.TempDir()
+	tests := []struct {
+		name string
+		c    Constraint
+		path string
+		ok   bool
+	}{
+		{"absolute inside", PathPrefix("path", "/data"), "/data/a.txt", true},
+		{"dir itself", PathPrefix("path", "/data"), "/data", true},
+		{"dot-dot escape", PathPrefix("path", "/data"), "/data/../etc/passwd", false},
+		{"sibling prefix", PathPrefix("path", "/data"), "/database/x", false},
+		{"relative denied", PathPrefix("path", "/data"), "a.txt", false},
+		{"root dir", PathPrefix("path", "/"), "/etc/hosts", true},
+		{"relative in workdir", PathPrefixIn(work, "path", work), "src/main.go", true},
+		{"relative dir in workdir", PathPrefixIn(work, "path", "src"), "src/main.go", true},
+		{"relative outside subdir", PathPrefixIn(work, "path", "src"), "docs/a.md", false},
+		{"relative escape", PathPrefixIn(work, "path", work), "../x", false},
+		{"absolute with workdir", PathPrefixIn(work, "path", "src"), filepath.Join(work, "src", "a"), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.c(map[string]any{"path": tt.path})
+			if (err == nil) != tt.ok {
+				t.Fatalf("path %q: err = %v, want allowed = %v", tt.path, err, tt.ok)
+			}
+		})
+	}
+
+	if err := PathPrefix("path", "/data")(map[string]any{}); err != nil {
+		t.Fatalf("missing field: %v", err)
+	}
+	if err := PathPrefix("path", "/data")(map[string]any{"path": 1}); err == nil {
+		t.Fatal("non-string path allowed")
+	}
+}
@@ -0,0 +1,142 @@
+package agentcore
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// RateLimiter blocks until a call may proceed. middleware.Limiter satisfies it.
+type RateLimiter interface {
+	Wait(ctx context.Context) (time.Duration, error)
+}
+
+// BatchOptions configures GenerateBatch.
+type BatchOptions struct {
+	SystemPrompt string       // prepended to every prompt when set
+	Concurrency  int          // parallel requests, default 4
+	MaxRetries   int          // per-item retries on retryable errors, default 2; < 0 disables
+	Limiter      RateLimiter  // optional, waited on before every request (including retries)
+	CallOptions  []CallOption // applied to every request
+
+	// OnProgress is called after each item finishes. Calls are serialized,
+	// so done increases by one each time.
+	OnProgress func(done, total int)
+}
+
+// BatchResult is the outcome of one prompt. Err is per item; other items
+// are unaffected.
+type BatchResult struct {
+	Message Message
+	Text    string
+	Err     error
+}
+
+// GenerateBatch sends each prompt as an independent single-turn request with
+// bounded concurrency, retrying retryable errors per item. Results are in
+// input order; usage is summed over all successful calls.
+//
+// Usage:
+//
+//	results, usage := agentcore.GenerateBatch(ctx, model, prompts, agentcore.BatchOptions{
+//	    SystemPrompt: "Classify the sentiment as positive, negative, or neutral. Reply with one word.",
+//	    Concurrency:  8,
+//	    OnProgress:   func(done, total int) { log.Printf("%d/%d", done, total) },
+//	})
+//	for i, r := range results {
+//	    if r.Err != nil { log.Printf("item %d: %v", i, r.Err); continue }
+//	    fmt.Println(r.Text)
+//	}
+func GenerateBatch(ctx context.Context, model ChatModel, prompts []string, opts BatchOptions) ([]BatchResult, Usage) {
+	results := make([]BatchResult, len(prompts))
+	if model == nil {
+		for i := range results {
+			results[i].Err = fmt.Errorf("no model configured")
+		}
+		return results, Usage{}
+	}
+
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = 4
+	}
+	maxRetries := opts.MaxRetries
+	if maxRetries == 0 {
+		maxRetries = 2
+	}
+
+	var (
+		mu    sync.Mutex
+		usage Usage
+		done  int
+		wg    sync.WaitGroup
+	)
+	sem := make(chan struct{}, concurrency)
+
+	for i, prompt := range prompts {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			for j := i; j < len(prompts); j++ {
+				results[j].Err = ctx.Err()
+			}
+			wg.Wait()
+			return results, usage
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			msg, err := generateWithRetry(ctx, model, batchMessages(opts.SystemPrompt, prompt), opts, maxRetries)
+			results[i] = BatchResult{Message: msg, Text: msg.TextContent(), Err: err}
+
+			mu.Lock()
+			if err == nil {
+				usage.Add(msg.Usage)
+			}
+			done++
+			if opts.OnProgress != nil {
+				opts.OnProgress(done, len(prompts))
+			}
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+	return results, usage
+}
+
+func batchMessages(system, prompt string) []Message {
+	if system == "" {
+		return []Message{UserMsg(prompt)}
+	}
+	return []Message{SystemMsg(system), UserMsg(prompt)}
+}
+
+// generateWithRetry calls Generate, backing off like the agent loop on
+// retryable errors and empty responses.
+func generateWithRetry(ctx context.Context, model ChatModel, msgs []Message, opts BatchOptions, maxRetries int) (Message, error) {
+	for attempt := 0; ; attempt++ {
+		if opts.Limiter != nil {
+			if _, err := opts.Limiter.Wait(ctx); err != nil {
+				return Message{}, err
+			}
+		}
+		resp, err := model.Generate(ctx, msgs, nil, opts.CallOptions...)
+		if err == nil {
+			if err = checkEmptyResponse(resp.Message); err == nil {
+				return resp.Message, nil
+			}
+		}
+		if attempt >= maxRetries || !isRetryableError(err) {
+			return Message{}, err
+		}
+		select {
+		case <-ctx.Done():
+			return Message{}, ctx.Err()
+		case <-time.After(retryDelay(err, attempt)):
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package agentcore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrBudgetExceeded is returned when a run's token or cost limit is reached.
+// Tool middlewares may return an error wrapping it to veto a step; the loop
+// then stops before the next LLM call.
+var ErrBudgetExceeded = errors.New("budget exceeded")
+
+// Budget caps the spend of a single run (one Prompt or Continue).
+// Zero fields are unlimited. MaxUSD relies on model pricing being known;
+// see llm.RegisterPricing for models missing from the default table.
+type Budget struct {
+	MaxTokens int     `json:"max_tokens"`
+	MaxUSD    float64 `json:"max_usd"`
+}
+
+type budgetKey struct{}
+
+// budgetTracker accumulates usage across the LLM calls of one run. Nested
+// runs (sub-agents) without their own budget share the parent's tracker so
+// their spend counts against the parent's cap.
+type budgetTracker struct {
+	mu     sync.Mutex
+	budget Budget
+	usage  Usage
+	last   Usage // most recent call, used to estimate the next one
+	vetoed error
+}
+
+// withBudgetTracker returns ctx carrying the tracker for this run.
+func withBudgetTracker(ctx context.Context, budget *Budget) (context.Context, *budgetTracker) {
+	if parent, ok := ctx.Value(budgetKey{}).(*budgetTracker); ok && budget == nil {
+		return ctx, parent
+	}
+	t := &budgetTracker{}
+	if budget != nil {
+		t.budget = *budget
+	}
+	return context.WithValue(ctx, budgetKey{}, t), t
+}
+
+func (t *budgetTracker) add(u *Usage) {
+	if u == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.usage.Add(u)
+	t.last = *u
+}
+
+// vetoBudget records a tool's ErrBudgetExceeded so the run stops before
+// the next LLM call.
+func vetoBudget(ctx context.Context, err error) {
+	t, ok := ctx.Value(budgetKey{}).(*budgetTracker)
+	if !ok {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.vetoed == nil {
+		t.vetoed = err
+	}
+}
+
+func (t *budgetTracker) snapshot() Usage {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	u := t.usage
+	if u.Cost != nil {
+		c := *u.Cost
+		u.Cost = &c
+	}
+	return u
+}
+
+// checkNext reports whether another LLM call fits in the budget. The next
+// prompt is at least as large as the last one, so the last call's input
+// tokens and cost serve as a lower-bound estimate.
+func (t *budgetTracker) checkNext() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.vetoed != nil {
+		return t.vetoed
+	}
+	if limit := t.budget.MaxTokens; limit > 0 {
+		if spent := usageTokens(t.usage); spent+t.last.Input > limit {
+			return fmt.Errorf("%w: %d tokens used, next call needs at least %d more (limit %d)",
+				ErrBudgetExceeded, spent, t.last.Input, limit)
+		}
+	}
+	if limit := t.budget.MaxUSD; limit > 0 && t.usage.Cost != nil {
+		var next float64
+		if t.last.Cost != nil {
+			next = t.last.Cost.Input
+		}
+		if spent := t.usage.Cost.Total; spent+next > limit {
+			return fmt.Errorf("%w: $%.4f spent, next call needs at least $%.4f more (limit $%.4f)",
+				ErrBudgetExceeded, spent, next, limit)
+		}
+	}
+	return nil
+}
+
+func usageTokens(u Usage) int {
+	if u.TotalTokens > 0 {
+		return u.TotalTokens
+	}
+	return u.Input + u.Output
+}
+
+// RunUsage returns the cumulative usage of the current run. It is available
+// to tools and tool middlewares through the execution context, so a
+// middleware can veto an expensive step:
+//
+//	func(ctx context.Context, call agentcore.ToolCall, next agentcore.ToolExecuteFunc) (json.RawMessage, error) {
+//	    if u, ok := agentcore.RunUsage(ctx); ok && u.Cost != nil && u.Cost.Total > 0.50 {
+//	        return nil, fmt.Errorf("%w: skipping %s", agentcore.ErrBudgetExceeded, call.Name)
+//	    }
+//	    return next(ctx, call.Args)
+//	}
+func RunUsage(ctx context.Context) (Usage, bool) {
+	t, ok := ctx.Value(budgetKey{}).(*budgetTracker)
+	if !ok {
+		return Usage{}, false
+	}
+	return t.snapshot(), true
+}
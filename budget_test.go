@@ -0,0 +1,135 @@
+package agentcore_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/voocel/agentcore"
+	"github.com/voocel/agentcore/llm/llmtest"
+)
+
+// withUsage attaches token usage and, when cost > 0, an input cost of cost
+// and an output cost of 2*cost to a scripted response.
+func withUsage(r llmtest.Response, input, output int, cost float64) llmtest.Response {
+	r.Message.Usage = &agentcore.Usage{Input: input, Output: output, TotalTokens: input + output}
+	if cost > 0 {
+		r.Message.Usage.Cost = &agentcore.Cost{Input: cost, Output: 2 * cost, Total: 3 * cost}
+	}
+	return r
+}
+
+func noopCall(id string) llmtest.Response {
+	return llmtest.ToolCalls(agentcore.ToolCall{ID: id, Name: "noop", Args: json.RawMessage(`{}`)})
+}
+
+func runError(events []agentcore.Event) error {
+	for _, ev := range events {
+		if ev.Type == agentcore.EventError {
+			return ev.Err
+		}
+	}
+	return nil
+}
+
+func TestBudgetStopsRun(t *testing.T) {
+	tests := []struct {
+		name   string
+		budget agentcore.Budget
+	}{
+		// Each call uses 120 tokens ($0.03) with 100 input tokens ($0.01):
+		// the third call's estimate of 240+100 tokens or $0.06+$0.01 is over.
+		{"tokens", agentcore.Budget{MaxTokens: 300}},
+		{"usd", agentcore.Budget{MaxUSD: 0.065}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			model := llmtest.NewModel(
+				withUsage(noopCall("1"), 100, 20, 0.01),
+				withUsage(noopCall("2"), 100, 20, 0.01),
+				withUsage(llmtest.Text("done"), 100, 20, 0.01),
+			)
+			noop := llmtest.NewTool("noop", nil)
+			agent := agentcore.NewAgent(agentcore.WithModel(model), agentcore.WithTools(noop), agentcore.WithBudget(tt.budget))
+
+			events := runEvents(t, agent, "go")
+			if err := runError(events); !errors.Is(err, agentcore.ErrBudgetExceeded) {
+				t.Fatalf("run error = %v, want ErrBudgetExceeded", err)
+			}
+			if len(model.Calls()) != 2 || len(noop.Calls()) != 2 {
+				t.Fatalf("model calls = %d, tool calls = %d; want the run stopped before the third call",
+					len(model.Calls()), len(noop.Calls()))
+			}
+			var last *agentcore.Usage
+			for _, ev := range events {
+				if ev.Type == agentcore.EventTurnEnd {
+					last = ev.Usage
+				}
+			}
+			if last == nil || last.TotalTokens != 240 {
+				t.Fatalf("run usage = %+v, want 240 tokens", last)
+			}
+		})
+	}
+}
+
+func TestBudgetToolVeto(t *testing.T) {
+	model := llmtest.NewModel(withUsage(noopCall("1"), 100, 20, 0), llmtest.Text("done"))
+	var seen agentcore.Usage
+	veto := func(ctx context.Context, call agentcore.ToolCall, next agentcore.ToolExecuteFunc) (json.RawMessage, error) {
+		seen, _ = agentcore.RunUsage(ctx)
+		return nil, fmt.Errorf("%w: skipping %s", agentcore.ErrBudgetExceeded, call.Name)
+	}
+	agent := agentcore.NewAgent(
+		agentcore.WithModel(model),
+		agentcore.WithTools(llmtest.NewTool("noop", nil)),
+		agentcore.WithMiddlewares(veto),
+	)
+
+	events := runEvents(t, agent, "go")
+	err := runError(events)
+	if !errors.Is(err, agentcore.ErrBudgetExceeded) || !strings.Contains(err.Error(), "skipping noop") {
+		t.Fatalf("run error = %v, want the middleware's veto", err)
+	}
+	if model.Remaining() != 1 {
+		t.Fatal("the run continued to another LLM call after the veto")
+	}
+	if seen.TotalTokens != 120 {
+		t.Fatalf("RunUsage in middleware = %+v, want the first call's 120 tokens", seen)
+	}
+	var vetoed bool
+	for _, ev := range events {
+		vetoed = vetoed || ev.Type == agentcore.EventToolExecEnd && ev.IsError
+	}
+	if !vetoed {
+		t.Fatal("no failed tool_exec_end event for the vetoed call")
+	}
+}
+
+func TestBudgetSharedWithSubAgent(t *testing.T) {
+	sub := llmtest.NewModel(withUsage(llmtest.Text("researched"), 100, 100, 0))
+	delegate := agentcore.NewSubAgentTool(agentcore.SubAgentConfig{Name: "researcher", Model: sub})
+	parent := llmtest.NewModel(
+		withUsage(llmtest.ToolCalls(agentcore.ToolCall{
+			ID: "1", Name: "subagent", Args: json.RawMessage(`{"agent":"researcher","task":"dig"}`),
+		}), 50, 10, 0),
+		withUsage(llmtest.Text("done"), 50, 10, 0),
+	)
+	agent := agentcore.NewAgent(
+		agentcore.WithModel(parent),
+		agentcore.WithTools(delegate),
+		agentcore.WithBudget(agentcore.Budget{MaxTokens: 250}),
+	)
+
+	// Parent 60 + sub-agent 200 = 260 tokens, already over the cap.
+	if err := runError(runEvents(t, agent, "go")); !errors.Is(err, agentcore.ErrBudgetExceeded) {
+		t.Fatalf("run error = %v, want the sub-agent's spend to count against the parent", err)
+	}
+	if len(sub.Calls()) != 1 || parent.Remaining() != 1 {
+		t.Fatalf("sub calls = %d, parent remaining = %d; want the parent stopped after the sub-agent",
+			len(sub.Calls()), parent.Remaining())
+	}
+}
@@ -0,0 +1,39 @@
+package agentcore
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrEmptyResponse matches (via errors.Is) an EmptyResponseError.
+var ErrEmptyResponse = errors.New("empty response from model")
+
+// EmptyResponseError is returned when the model replies with no text and no
+// tool calls, e.g. after a content filter or an early stop token. The agent
+// loop retries it like a transient provider error.
+type EmptyResponseError struct {
+	StopReason StopReason // finish reason reported by the provider, if any
+}
+
+func (e *EmptyResponseError) Error() string {
+	if e.StopReason == "" {
+		return ErrEmptyResponse.Error()
+	}
+	return fmt.Sprintf("%s (stop reason %q)", ErrEmptyResponse, e.StopReason)
+}
+
+func (e *EmptyResponseError) Is(target error) bool { return target == ErrEmptyResponse }
+
+// checkEmptyResponse returns an *EmptyResponseError when msg has neither
+// non-whitespace text nor tool calls. Error and aborted stops are reported
+// by their own paths and pass through.
+func checkEmptyResponse(msg Message) error {
+	if msg.StopReason == StopReasonError || msg.StopReason == StopReasonAborted {
+		return nil
+	}
+	if strings.TrimSpace(msg.TextContent()) != "" || len(msg.ToolCalls()) > 0 {
+		return nil
+	}
+	return &EmptyResponseError{StopReason: msg.StopReason}
+}
@@ -0,0 +1,95 @@
+package agentcore_test
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/voocel/agentcore"
+	"github.com/voocel/agentcore/llm/llmtest"
+)
+
+// runEvents prompts agent and returns the events of the run.
+func runEvents(t *testing.T, agent *agentcore.Agent, input string) []agentcore.Event {
+	t.Helper()
+	var (
+		mu     sync.Mutex
+		events []agentcore.Event
+	)
+	unsubscribe := agent.Subscribe(func(ev agentcore.Event) {
+		mu.Lock()
+		events = append(events, ev)
+		mu.Unlock()
+	})
+	defer unsubscribe()
+	if err := agent.Prompt(input); err != nil {
+		t.Fatal(err)
+	}
+	agent.WaitForIdle()
+	mu.Lock()
+	defer mu.Unlock()
+	return append([]agentcore.Event(nil), events...)
+}
+
+func TestEmptyResponseIsAnError(t *testing.T) {
+	agent := agentcore.NewAgent(agentcore.WithModel(llmtest.NewModel(llmtest.Text(" \n "))), agentcore.WithMaxRetries(0))
+
+	var runErr error
+	for _, ev := range runEvents(t, agent, "hi") {
+		if ev.Type == agentcore.EventError {
+			runErr = ev.Err
+		}
+	}
+	if !errors.Is(runErr, agentcore.ErrEmptyResponse) {
+		t.Fatalf("run error = %v, want ErrEmptyResponse", runErr)
+	}
+	var empty *agentcore.EmptyResponseError
+	if !errors.As(runErr, &empty) || empty.StopReason != agentcore.StopReasonStop {
+		t.Fatalf("run error = %#v, want *EmptyResponseError with stop reason %q", runErr, agentcore.StopReasonStop)
+	}
+}
+
+func TestEmptyResponseIsRetried(t *testing.T) {
+	model := llmtest.NewModel(llmtest.Text(""), llmtest.Text("ok"))
+	agent := agentcore.NewAgent(agentcore.WithModel(model), agentcore.WithMaxRetries(1))
+
+	var retried bool
+	var final []agentcore.AgentMessage
+	for _, ev := range runEvents(t, agent, "hi") {
+		switch ev.Type {
+		case agentcore.EventRetry:
+			retried = errors.Is(ev.Err, agentcore.ErrEmptyResponse)
+		case agentcore.EventError:
+			t.Fatalf("unexpected error: %v", ev.Err)
+		case agentcore.EventAgentEnd:
+			final = ev.NewMessages
+		}
+	}
+	if !retried {
+		t.Fatal("no retry event for the empty response")
+	}
+	if len(final) == 0 || final[len(final)-1].TextContent() != "ok" {
+		t.Fatalf("final messages = %v, want the retried reply", final)
+	}
+	if model.Remaining() != 0 {
+		t.Fatalf("%d scripted responses unused", model.Remaining())
+	}
+}
+
+func TestToolCallOnlyResponseIsNotEmpty(t *testing.T) {
+	tool := llmtest.NewTool("noop", nil)
+	model := llmtest.NewModel(
+		llmtest.ToolCalls(agentcore.ToolCall{ID: "1", Name: "noop", Args: []byte(`{}`)}),
+		llmtest.Text("done"),
+	)
+	agent := agentcore.NewAgent(agentcore.WithModel(model), agentcore.WithTools(tool))
+
+	for _, ev := range runEvents(t, agent, "hi") {
+		if ev.Type == agentcore.EventError {
+			t.Fatalf("unexpected error: %v", ev.Err)
+		}
+	}
+	if len(tool.Calls()) != 1 {
+		t.Fatalf("tool called %d times, want 1", len(tool.Calls()))
+	}
+}
@@ -0,0 +1,222 @@
+// Package eventlog persists agent events with sequence numbers so past runs
+// can be audited, replayed, or streamed (history first, then live).
+//
+// Usage:
+//
+//	store, _ := eventlog.NewFileStore("runs/session-42.jsonl")
+//	defer store.Close()
+//	log, _ := eventlog.New(store)
+//	detach := log.Attach(agent) // attach before other subscribers
+//	defer detach()
+//
+//	// Later: inspect a past run
+//	log.Replay(ctx, 1, 0, func(r eventlog.Record) error {
+//	    fmt.Println(r.Seq, r.Type, r.Tool)
+//	    return nil
+//	})
+package eventlog
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/voocel/agentcore"
+)
+
+// Record is the persisted form of an agent event. Errors are flattened to
+// strings and custom AgentMessage types that are not agentcore.Message are
+// omitted, so every field round-trips through JSON. Streaming updates keep
+// only their delta; the full message is recorded by message_end.
+type Record struct {
+	Seq         uint64                    `json:"seq"`
+	Time        time.Time                 `json:"time"`
+	Type        agentcore.EventType       `json:"type"`
+	Message     *agentcore.Message        `json:"message,omitempty"`
+	Delta       string                    `json:"delta,omitempty"`
+	DeltaType   agentcore.StreamEventType `json:"delta_type,omitempty"`
+	ToolID      string                    `json:"tool_id,omitempty"`
+	Tool        string                    `json:"tool,omitempty"`
+	Args        json.RawMessage           `json:"args,omitempty"`
+	Result      json.RawMessage           `json:"result,omitempty"`
+	IsError     bool                      `json:"is_error,omitempty"`
+	ToolResults []agentcore.ToolResult    `json:"tool_results,omitempty"`
+	Usage       *agentcore.Usage          `json:"usage,omitempty"`
+	Error       string                    `json:"error,omitempty"`
+	TraceID     string                    `json:"trace_id,omitempty"`
+	RunID       string                    `json:"run_id,omitempty"`
+	ParentRunID string                    `json:"parent_run_id,omitempty"`
+}
+
+// Store is an append-only, sequence-ordered event store.
+type Store interface {
+	// Append persists a record. Records arrive in increasing Seq order.
+	Append(rec Record) error
+	// Range calls fn for records with from <= Seq <= to, in order.
+	// to == 0 means through the latest record.
+	Range(ctx context.Context, from, to uint64, fn func(Record) error) error
+	// LastSeq returns the highest stored sequence number, 0 if empty.
+	LastSeq() (uint64, error)
+}
+
+// NewRecord converts an event to its persisted form (Seq and Time unset).
+func NewRecord(ev agentcore.Event) Record {
+	rec := Record{
+		Type:        ev.Type,
+		Delta:       ev.Delta,
+		DeltaType:   ev.DeltaType,
+		ToolID:      ev.ToolID,
+		Tool:        ev.Tool,
+		Args:        rawJSON(ev.Args),
+		Result:      rawJSON(ev.Result),
+		IsError:     ev.IsError,
+		ToolResults: ev.ToolResults,
+		Usage:       ev.Usage,
+		TraceID:     ev.TraceID,
+		RunID:       ev.RunID,
+		ParentRunID: ev.ParentRunID,
+	}
+	// Each update carries the whole partial message; storing it would make
+	// the log quadratic in the response length.
+	if msg, ok := ev.Message.(agentcore.Message); ok && ev.Type != agentcore.EventMessageUpdate {
+		rec.Message = &msg
+	}
+	switch {
+	case ev.Err != nil:
+		rec.Error = ev.Err.Error()
+	case ev.RetryInfo != nil && ev.RetryInfo.Err != nil:
+		rec.Error = ev.RetryInfo.Err.Error()
+	}
+	return rec
+}
+
+// rawJSON keeps valid JSON as-is and wraps anything else (e.g. malformed
+// tool arguments from the model) in a JSON string so the record still encodes.
+func rawJSON(b []byte) json.RawMessage {
+	if len(b) == 0 || json.Valid(b) {
+		return b
+	}
+	quoted, _ := json.Marshal(string(b))
+	return quoted
+}
+
+// Log assigns sequence numbers, writes events to a Store, and fans them out
+// to live streams after they are persisted.
+type Log struct {
+	store Store
+
+	mu      sync.Mutex
+	seq     uint64
+	streams map[chan Record]chan struct{} // live channel -> lag signal
+}
+
+// New creates a Log over store, continuing after its last sequence number.
+func New(store Store) (*Log, error) {
+	last, err := store.LastSeq()
+	if err != nil {
+		return nil, err
+	}
+	return &Log{store: store, seq: last, streams: make(map[chan Record]chan struct{})}, nil
+}
+
+// Append persists an event and notifies live streams.
+func (l *Log) Append(ev agentcore.Event) (Record, error) {
+	rec := NewRecord(ev)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	rec.Seq = l.seq + 1
+	rec.Time = time.Now()
+	if err := l.store.Append(rec); err != nil {
+		return Record{}, err
+	}
+	l.seq = rec.Seq
+
+	for ch, lagged := range l.streams {
+		select {
+		case ch <- rec:
+		default: // stream lagging; signal it to catch up from the store
+			select {
+			case lagged <- struct{}{}:
+			default:
+			}
+		}
+	}
+	return rec, nil
+}
+
+// Attach subscribes the log to an agent's events. Attach before other
+// subscribers so events are persisted before they are dispatched to them.
+// Store errors are dropped; use Append directly to handle them.
+func (l *Log) Attach(a *agentcore.Agent) (detach func()) {
+	return a.Subscribe(func(ev agentcore.Event) { _, _ = l.Append(ev) })
+}
+
+// Replay calls fn for stored records with from <= Seq <= to (to == 0: latest).
+func (l *Log) Replay(ctx context.Context, from, to uint64, fn func(Record) error) error {
+	return l.store.Range(ctx, from, to, fn)
+}
+
+// Stream replays records from seq from onward, then continues with live
+// records until ctx is canceled. No record is skipped or duplicated across
+// the switch to live.
+func (l *Log) Stream(ctx context.Context, from uint64) <-chan Record {
+	out := make(chan Record, 64)
+	live := make(chan Record, 256)
+	lagged := make(chan struct{}, 1)
+
+	// Register before replaying so nothing appended meanwhile is missed.
+	l.mu.Lock()
+	l.streams[live] = lagged
+	l.mu.Unlock()
+
+	go func() {
+		defer close(out)
+		defer func() {
+			l.mu.Lock()
+			delete(l.streams, live)
+			l.mu.Unlock()
+		}()
+
+		next := max(from, 1)
+		send := func(r Record) error {
+			select {
+			case out <- r:
+				next = r.Seq + 1
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+
+		if l.store.Range(ctx, next, 0, send) != nil {
+			return
+		}
+		for {
+			select {
+			case r := <-live:
+				switch {
+				case r.Seq < next:
+					continue // already replayed
+				case r.Seq > next:
+					// Dropped live records: fill the gap from the store.
+					if l.store.Range(ctx, next, r.Seq, send) != nil {
+						return
+					}
+				default:
+					if send(r) != nil {
+						return
+					}
+				}
+			case <-lagged:
+				if l.store.Range(ctx, next, 0, send) != nil {
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out
+}
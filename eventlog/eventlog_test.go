@@ -0,0 +1,98 @@
+package eventlog
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/voocel/agentcore"
+)
+
+func TestFileStoreRecoversPartialLine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+	store, err := NewFileStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for seq := uint64(1); seq <= 2; seq++ {
+		if err := store.Append(Record{Seq: seq, Type: agentcore.EventTurnStart}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	store.Close()
+
+	// Simulate a crash in the middle of writing record 3.
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.WriteString(`{"seq":3,"ty`); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	store, err = NewFileStore(path)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	defer store.Close()
+	if last, _ := store.LastSeq(); last != 2 {
+		t.Fatalf("LastSeq = %d, want 2", last)
+	}
+	if err := store.Append(Record{Seq: 3, Type: agentcore.EventTurnEnd}); err != nil {
+		t.Fatal(err)
+	}
+
+	var seqs []uint64
+	err = store.Range(context.Background(), 0, 0, func(r Record) error {
+		seqs = append(seqs, r.Seq)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Range after recovery: %v", err)
+	}
+	if len(seqs) != 3 || seqs[2] != 3 {
+		t.Fatalf("seqs = %v, want [1 2 3]", seqs)
+	}
+}
+
+func TestFileStoreRecoversUnterminatedFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+	if err := os.WriteFile(path, []byte(`{"seq":1,`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	store, err := NewFileStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	if last, _ := store.LastSeq(); last != 0 {
+		t.Fatalf("LastSeq = %d, want 0", last)
+	}
+	if info, _ := os.Stat(path); info.Size() != 0 {
+		t.Fatalf("size = %d, want partial line truncated", info.Size())
+	}
+}
+
+func TestNewRecordMessageUpdate(t *testing.T) {
+	msg := agentcore.Message{Role: agentcore.RoleAssistant, Content: []agentcore.ContentBlock{agentcore.TextBlock("hello wor")}}
+
+	rec := NewRecord(agentcore.Event{
+		Type:      agentcore.EventMessageUpdate,
+		Message:   msg,
+		Delta:     "wor",
+		DeltaType: agentcore.StreamEventTextDelta,
+	})
+	if rec.Message != nil {
+		t.Fatalf("message_update record kept the message: %+v", rec.Message)
+	}
+	if rec.Delta != "wor" || rec.DeltaType != agentcore.StreamEventTextDelta {
+		t.Fatalf("delta = %q (%s), want %q (%s)", rec.Delta, rec.DeltaType, "wor", agentcore.StreamEventTextDelta)
+	}
+
+	rec = NewRecord(agentcore.Event{Type: agentcore.EventMessageEnd, Message: msg})
+	if rec.Message == nil || rec.Message.TextContent() != "hello wor" {
+		t.Fatalf("message_end record lost the message: %+v", rec.Message)
+	}
+}
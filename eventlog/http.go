@@ -0,0 +1,115 @@
+package eventlog
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/voocel/agentcore"
+)
+
+// heartbeatInterval keeps idle connections open through proxies.
+const heartbeatInterval = 15 * time.Second
+
+// Handler streams records to browsers as Server-Sent Events, one event per
+// record with the sequence number as its id and the event type as its name.
+//
+// Query parameters:
+//
+//	types  comma-separated event type patterns, e.g. "tool_exec_*,message_end"
+//	       (same rules as agentcore.WithEventFilter); default all
+//	from   first sequence number to send; default only new records
+//
+// Reconnecting EventSource clients send Last-Event-ID and resume after it, so
+// no record is lost across reconnects.
+//
+// Usage:
+//
+//	http.Handle("/events", log.Handler())
+//
+//	// In the browser:
+//	// const es = new EventSource("/events?types=tool_exec_*,turn_end");
+//	// es.addEventListener("tool_exec_end", e => render(JSON.parse(e.data)));
+func (l *Log) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+			return
+		}
+
+		var patterns []string
+		if v := r.URL.Query().Get("types"); v != "" {
+			patterns = strings.Split(v, ",")
+		}
+		from, err := l.startSeq(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.WriteHeader(http.StatusOK)
+		flusher.Flush()
+
+		heartbeat := time.NewTicker(heartbeatInterval)
+		defer heartbeat.Stop()
+
+		// Stream stops when the client disconnects and r.Context() is canceled.
+		records := l.Stream(r.Context(), from)
+		for {
+			select {
+			case rec, ok := <-records:
+				if !ok {
+					return
+				}
+				if len(patterns) > 0 && !agentcore.MatchEventType(rec.Type, patterns...) {
+					continue
+				}
+				data, err := json.Marshal(rec)
+				if err != nil {
+					continue
+				}
+				if _, err := fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", rec.Seq, rec.Type, data); err != nil {
+					return
+				}
+				flusher.Flush()
+			case <-heartbeat.C:
+				if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+					return
+				}
+				flusher.Flush()
+			}
+		}
+	})
+}
+
+// startSeq resolves where a stream begins: after Last-Event-ID on reconnect,
+// at ?from= if given, otherwise after the latest record.
+func (l *Log) startSeq(r *http.Request) (uint64, error) {
+	if v := r.Header.Get("Last-Event-ID"); v != "" {
+		id, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid Last-Event-ID %q", v)
+		}
+		return id + 1, nil
+	}
+	if v := r.URL.Query().Get("from"); v != "" {
+		from, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid from %q", v)
+		}
+		return from, nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.seq + 1, nil
+}
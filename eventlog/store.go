@@ -0,0 +1,190 @@
+package eventlog
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"sync"
+)
+
+// MemoryStore keeps records in memory. Useful for tests and short-lived processes.
+type MemoryStore struct {
+	mu      sync.RWMutex
+	records []Record
+}
+
+// NewMemoryStore creates an empty in-memory store.
+func NewMemoryStore() *MemoryStore { return &MemoryStore{} }
+
+func (s *MemoryStore) Append(rec Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.records = append(s.records, rec)
+	return nil
+}
+
+func (s *MemoryStore) Range(ctx context.Context, from, to uint64, fn func(Record) error) error {
+	s.mu.RLock()
+	snapshot := s.records
+	s.mu.RUnlock()
+	for _, rec := range snapshot {
+		if rec.Seq < from || (to > 0 && rec.Seq > to) {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := fn(rec); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *MemoryStore) LastSeq() (uint64, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if len(s.records) == 0 {
+		return 0, nil
+	}
+	return s.records[len(s.records)-1].Seq, nil
+}
+
+// FileStore appends records to a JSONL file, one record per line.
+// A partially written trailing line left by a crash is truncated on open,
+// so appends always start on a fresh line.
+type FileStore struct {
+	path string
+
+	mu   sync.Mutex
+	file *os.File
+	last uint64
+}
+
+// NewFileStore opens or creates a JSONL event file for appending.
+func NewFileStore(path string) (*FileStore, error) {
+	if err := truncatePartialLine(path); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("open event log: %w", err)
+	}
+	s := &FileStore{path: path, file: f}
+	err = s.Range(context.Background(), 0, 0, func(r Record) error {
+		s.last = r.Seq
+		return nil
+	})
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// truncatePartialLine cuts the file after its last '\n', dropping a record
+// whose write was interrupted.
+func truncatePartialLine(path string) error {
+	f, err := os.OpenFile(path, os.O_RDWR, 0)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("open event log: %w", err)
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return fmt.Errorf("open event log: %w", err)
+	}
+
+	size := info.Size()
+	end := int64(0)
+	buf := make([]byte, 4096)
+	for off := size; off > 0; {
+		n := min(int64(len(buf)), off)
+		off -= n
+		if _, err := f.ReadAt(buf[:n], off); err != nil {
+			return fmt.Errorf("read event log: %w", err)
+		}
+		if i := bytes.LastIndexByte(buf[:n], '\n'); i >= 0 {
+			end = off + int64(i) + 1
+			break
+		}
+	}
+	if end == size {
+		return nil
+	}
+	if err := f.Truncate(end); err != nil {
+		return fmt.Errorf("recover event log: %w", err)
+	}
+	return nil
+}
+
+func (s *FileStore) Append(rec Record) error {
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("marshal event: %w", err)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := s.file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("write event: %w", err)
+	}
+	s.last = rec.Seq
+	return nil
+}
+
+func (s *FileStore) Range(ctx context.Context, from, to uint64, fn func(Record) error) error {
+	f, err := os.Open(s.path)
+	if err != nil {
+		return fmt.Errorf("open event log: %w", err)
+	}
+	defer f.Close()
+
+	r := bufio.NewReader(f)
+	for {
+		line, err := r.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
+			return nil // a trailing line without '\n' is incomplete
+		}
+		if err != nil {
+			return fmt.Errorf("read event log: %w", err)
+		}
+		var rec Record
+		if err := json.Unmarshal(line, &rec); err != nil {
+			return fmt.Errorf("parse event log %s: %w", s.path, err)
+		}
+		if rec.Seq < from {
+			continue
+		}
+		if to > 0 && rec.Seq > to {
+			return nil
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := fn(rec); err != nil {
+			return err
+		}
+	}
+}
+
+func (s *FileStore) LastSeq() (uint64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.last, nil
+}
+
+// Close closes the underlying file.
+func (s *FileStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.file.Close()
+}
@@ -0,0 +1,105 @@
+package agentcore
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+)
+
+// Guard inspects text entering or leaving the agent. Return the text
+// (possibly rewritten, e.g. redacted) to continue, or an error to abort the
+// run with a GuardrailError.
+type Guard func(ctx context.Context, text string) (string, error)
+
+// GuardStage identifies where a guard ran.
+type GuardStage string
+
+const (
+	GuardInput  GuardStage = "input"  // user messages before they reach the model
+	GuardOutput GuardStage = "output" // assistant text before it is emitted and stored
+)
+
+// GuardrailError is returned when a guard rejects content.
+type GuardrailError struct {
+	Stage GuardStage
+	Err   error
+}
+
+func (e *GuardrailError) Error() string {
+	return fmt.Sprintf("%s guardrail: %v", e.Stage, e.Err)
+}
+
+func (e *GuardrailError) Unwrap() error { return e.Err }
+
+// guardMessage runs guards over every text block of msg, in order.
+func guardMessage(ctx context.Context, stage GuardStage, guards []Guard, msg Message) (Message, error) {
+	if len(guards) == 0 {
+		return msg, nil
+	}
+	content := make([]ContentBlock, len(msg.Content))
+	copy(content, msg.Content)
+	for i, b := range content {
+		if b.Type != ContentText {
+			continue
+		}
+		text := b.Text
+		for _, g := range guards {
+			var err error
+			if text, err = g(ctx, text); err != nil {
+				return msg, &GuardrailError{Stage: stage, Err: err}
+			}
+		}
+		content[i].Text = text
+	}
+	msg.Content = content
+	return msg, nil
+}
+
+// guardInputs applies input guards to user messages; other roles and custom
+// AgentMessage types pass through unchanged.
+func guardInputs(ctx context.Context, guards []Guard, msgs []AgentMessage) ([]AgentMessage, error) {
+	if len(guards) == 0 {
+		return msgs, nil
+	}
+	out := make([]AgentMessage, len(msgs))
+	for i, m := range msgs {
+		msg, ok := m.(Message)
+		if !ok || msg.Role != RoleUser {
+			out[i] = m
+			continue
+		}
+		guarded, err := guardMessage(ctx, GuardInput, guards, msg)
+		if err != nil {
+			return nil, err
+		}
+		out[i] = guarded
+	}
+	return out, nil
+}
+
+var piiPatterns = []struct {
+	re          *regexp.Regexp
+	replacement string
+}{
+	{regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`), "[EMAIL]"},
+	{regexp.MustCompile(`\b\d{3}-\d{2}-\d{4}\b`), "[SSN]"},
+	{regexp.MustCompile(`\b(?:\d[ -]?){13,16}\b`), "[CARD]"},
+	{regexp.MustCompile(`(?:\+\d{1,3}[ .-]?)?\(?\b\d{3}\)?[ .-]?\d{3}[ .-]?\d{4}\b`), "[PHONE]"},
+}
+
+// RedactPII is an example Guard that masks email addresses, US social
+// security numbers, card numbers, and phone numbers with regular expressions.
+// It is a starting point, not a complete PII detector.
+//
+// Usage:
+//
+//	agentcore.NewAgent(
+//	    agentcore.WithInputGuard(agentcore.RedactPII),
+//	    agentcore.WithOutputGuard(agentcore.RedactPII),
+//	)
+func RedactPII(_ context.Context, text string) (string, error) {
+	for _, p := range piiPatterns {
+		text = p.re.ReplaceAllString(text, p.replacement)
+	}
+	return text, nil
+}
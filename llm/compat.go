@@ -0,0 +1,365 @@
+package llm
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/voocel/agentcore"
+	"github.com/voocel/litellm"
+)
+
+// Capabilities describes what an OpenAI-compatible endpoint actually supports.
+// Local servers (Ollama, llama.cpp, vLLM) often accept the OpenAI wire format
+// but reject or silently ignore some fields depending on the loaded model.
+type Capabilities struct {
+	SupportsTools     bool `json:"supports_tools"`
+	SupportsStreaming bool `json:"supports_streaming"`
+	SupportsJSONMode  bool `json:"supports_json_mode"`
+	SupportsImages    bool `json:"supports_images"`
+}
+
+// DefaultCapabilities assumes a fully featured OpenAI-compatible endpoint.
+var DefaultCapabilities = Capabilities{
+	SupportsTools:     true,
+	SupportsStreaming: true,
+	SupportsJSONMode:  true,
+	SupportsImages:    true,
+}
+
+// OpenAICompatibleModel adapts any OpenAI-compatible endpoint.
+//
+// When the endpoint does not support native tool calling, tool specs are
+// injected into the system prompt and tool calls are parsed back out of the
+// text response. The returned Message carries regular ToolCall blocks either
+// way, so the agent loop executes tools identically in both modes.
+//
+// Capabilities can be declared up front or probed once via ProbeCapabilities;
+// the result is cached on the model and reused for every later call.
+type OpenAICompatibleModel struct {
+	*LiteLLMAdapter
+
+	mu     sync.RWMutex
+	caps   Capabilities
+	probed bool
+}
+
+// NewOpenAICompatibleProvider creates an adapter for an OpenAI-compatible
+// endpoint (e.g. Ollama at http://localhost:11434/v1) with declared capabilities.
+// apiKey may be empty for local servers that don't require authentication.
+func NewOpenAICompatibleProvider(model, baseURL, apiKey string, caps Capabilities) (*OpenAICompatibleModel, error) {
+	if apiKey == "" {
+		apiKey = "local" // most SDK paths reject an empty key even when the server ignores it
+	}
+	adapter, err := newProviderAdapter("openai", model, apiKey, baseURL)
+	if err != nil {
+		return nil, err
+	}
+	adapter.SetCapability(CapabilityMultimodal, caps.SupportsImages)
+	return &OpenAICompatibleModel{LiteLLMAdapter: adapter, caps: caps}, nil
+}
+
+// Capabilities returns the currently cached capabilities.
+func (m *OpenAICompatibleModel) Capabilities() Capabilities {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.caps
+}
+
+// SetCapabilities overrides the cached capabilities (e.g. restored from config).
+func (m *OpenAICompatibleModel) SetCapabilities(caps Capabilities) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.caps = caps
+	m.probed = true
+	m.SetCapability(CapabilityMultimodal, caps.SupportsImages)
+}
+
+// ProbeCapabilities detects tool and streaming support by issuing minimal
+// requests against the endpoint. The probe runs once; subsequent calls return
+// the cached result. JSON mode cannot be detected reliably and keeps its
+// declared value.
+//
+// The requests run without holding the model's lock, so concurrent Generate
+// and Capabilities calls are not blocked; if capabilities are set while the
+// probe is in flight, they win and are returned instead.
+//
+// Typical usage is to probe at startup and persist the result:
+//
+//	model, _ := llm.NewOpenAICompatibleProvider("qwen2.5", "http://localhost:11434/v1", "", llm.DefaultCapabilities)
+//	caps, err := model.ProbeCapabilities(ctx)
+func (m *OpenAICompatibleModel) ProbeCapabilities(ctx context.Context) (Capabilities, error) {
+	m.mu.RLock()
+	caps, probed := m.caps, m.probed
+	m.mu.RUnlock()
+	if probed {
+		return caps, nil
+	}
+
+	maxTokens := 64
+
+	// Tools: supported only if the model actually calls the tool. A rejected
+	// request or an ignored tool list both count as unsupported.
+	toolReq := &litellm.Request{
+		Model:     m.model,
+		Messages:  convertMessages([]Message{agentcore.UserMsg("Call the noop tool.")}),
+		MaxTokens: &maxTokens,
+	}
+	applyToolConfig(toolReq, []ToolSpec{{
+		Name:        "noop",
+		Description: "Does nothing. Call it to reply.",
+		Parameters:  map[string]any{"type": "object", "properties": map[string]any{}},
+	}})
+	resp, err := m.client.Chat(ctx, toolReq)
+	if err != nil && ctx.Err() != nil {
+		return caps, ctx.Err()
+	}
+	caps.SupportsTools = err == nil && len(resp.ToolCalls) > 0
+
+	// Streaming: the stream must open and yield at least one chunk.
+	streamReq := &litellm.Request{
+		Model:     m.model,
+		Messages:  convertMessages([]Message{agentcore.UserMsg("Reply with the single word: ok")}),
+		MaxTokens: &maxTokens,
+	}
+	if stream, err := m.client.Stream(ctx, streamReq); err != nil {
+		if ctx.Err() != nil {
+			return caps, ctx.Err()
+		}
+		caps.SupportsStreaming = false
+	} else {
+		_, nextErr := stream.Next()
+		stream.Close()
+		caps.SupportsStreaming = nextErr == nil
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.probed {
+		return m.caps, nil
+	}
+	m.caps.SupportsTools = caps.SupportsTools
+	m.caps.SupportsStreaming = caps.SupportsStreaming
+	m.probed = true
+	return m.caps, nil
+}
+
+// SupportsTools reports true: native or emulated, tool calls always reach the agent.
+func (m *OpenAICompatibleModel) SupportsTools() bool { return true }
+
+// Generate produces a synchronous response, emulating tool calls when needed.
+func (m *OpenAICompatibleModel) Generate(ctx context.Context, messages []Message, tools []ToolSpec, opts ...CallOption) (*LLMResponse, error) {
+	opts = m.filterOptions(opts)
+	if len(tools) == 0 || m.Capabilities().SupportsTools {
+		return m.LiteLLMAdapter.Generate(ctx, messages, tools, opts...)
+	}
+
+	resp, err := m.LiteLLMAdapter.Generate(ctx, InjectToolPrompt(messages, tools), nil, opts...)
+	if err != nil {
+		return nil, err
+	}
+	resp.Message = ExtractToolCalls(resp.Message)
+	return resp, nil
+}
+
+// GenerateStream streams when supported. Emulated tool calling buffers the
+// full response so tool-call markup never leaks into text deltas.
+func (m *OpenAICompatibleModel) GenerateStream(ctx context.Context, messages []Message, tools []ToolSpec, opts ...CallOption) (<-chan StreamEvent, error) {
+	caps := m.Capabilities()
+	opts = m.filterOptions(opts)
+	emulateTools := len(tools) > 0 && !caps.SupportsTools
+
+	if caps.SupportsStreaming && !emulateTools {
+		return m.LiteLLMAdapter.GenerateStream(ctx, messages, tools, opts...)
+	}
+
+	resp, err := m.Generate(ctx, messages, tools, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return agentcore.StreamMessage(resp.Message), nil
+}
+
+// filterOptions drops the response schema for endpoints without JSON mode,
+// which tend to reject unknown response_format values outright.
+func (m *OpenAICompatibleModel) filterOptions(opts []CallOption) []CallOption {
+	if m.Capabilities().SupportsJSONMode {
+		return opts
+	}
+	return append(opts, func(c *agentcore.CallConfig) { c.ResponseSchema = nil })
+}
+
+// ---------------------------------------------------------------------------
+// Prompt-injected tool calling
+// ---------------------------------------------------------------------------
+
+const toolPromptHeader = `You have access to the following tools. To call a tool, respond with one or more blocks in this exact format and nothing else inside the tags:
+
+<tool_call>
+{"name": "<tool name>", "arguments": {<JSON arguments>}}
+</tool_call>
+
+Wait for the tool results before answering. If no tool is needed, answer normally without any <tool_call> blocks.
+
+Available tools:
+`
+
+var toolCallPattern = regexp.MustCompile(`(?s)<tool_call>\s*(.*?)\s*</tool_call>`)
+
+// InjectToolPrompt rewrites a conversation for endpoints without native tool
+// support: tool specs are appended to the system prompt, prior assistant tool
+// calls become <tool_call> text, and tool results become user messages.
+func InjectToolPrompt(messages []Message, tools []ToolSpec) []Message {
+	var sb strings.Builder
+	sb.WriteString(toolPromptHeader)
+	for _, t := range tools {
+		params, _ := json.Marshal(t.Parameters)
+		fmt.Fprintf(&sb, "\n- %s: %s\n  parameters: %s\n", t.Name, t.Description, params)
+	}
+	toolPrompt := sb.String()
+
+	out := make([]Message, 0, len(messages)+1)
+	hasSystem := false
+	for _, msg := range messages {
+		switch msg.Role {
+		case agentcore.RoleSystem:
+			if !hasSystem {
+				hasSystem = true
+				msg = agentcore.SystemMsg(msg.TextContent() + "\n\n" + toolPrompt)
+			}
+		case agentcore.RoleAssistant:
+			if msg.HasToolCalls() {
+				msg = assistantToolCallsAsText(msg)
+			}
+		case agentcore.RoleTool:
+			id, _ := msg.Metadata["tool_call_id"].(string)
+			msg = agentcore.UserMsg(fmt.Sprintf("<tool_result id=%q>\n%s\n</tool_result>", id, msg.TextContent()))
+		}
+		out = append(out, msg)
+	}
+	if !hasSystem {
+		out = append([]Message{agentcore.SystemMsg(toolPrompt)}, out...)
+	}
+	return out
+}
+
+// assistantToolCallsAsText replaces ToolCall blocks with their <tool_call> text form.
+func assistantToolCallsAsText(msg Message) Message {
+	content := make([]agentcore.ContentBlock, 0, len(msg.Content))
+	for _, b := range msg.Content {
+		if b.Type != agentcore.ContentToolCall || b.ToolCall == nil {
+			content = append(content, b)
+			continue
+		}
+		args := b.ToolCall.Args
+		if len(args) == 0 {
+			args = json.RawMessage("{}")
+		}
+		call, _ := json.Marshal(struct {
+			Name      string          `json:"name"`
+			Arguments json.RawMessage `json:"arguments"`
+		}{b.ToolCall.Name, args})
+		content = append(content, agentcore.TextBlock("<tool_call>\n"+string(call)+"\n</tool_call>"))
+	}
+	msg.Content = content
+	return msg
+}
+
+var (
+	idGenMu sync.RWMutex
+	idGen   = randomToolCallID
+)
+
+// SetToolCallIDGenerator replaces the generator for IDs of tool calls parsed
+// from text, e.g. with a counter for deterministic tests. nil restores the
+// default random IDs.
+func SetToolCallIDGenerator(fn func() string) {
+	idGenMu.Lock()
+	defer idGenMu.Unlock()
+	if fn == nil {
+		fn = randomToolCallID
+	}
+	idGen = fn
+}
+
+func newToolCallID() string {
+	idGenMu.RLock()
+	defer idGenMu.RUnlock()
+	return idGen()
+}
+
+// randomToolCallID returns "call_" plus 64 random bits, so IDs don't collide
+// when many calls are parsed within the same clock tick.
+func randomToolCallID() string {
+	var b [8]byte
+	_, _ = rand.Read(b[:])
+	return "call_" + hex.EncodeToString(b[:])
+}
+
+// ExtractToolCalls parses <tool_call> blocks out of an assistant message's
+// text and converts them to ToolCall content blocks. Text outside the tags is
+// kept. Messages without parseable tool calls are returned unchanged.
+func ExtractToolCalls(msg Message) Message {
+	text := msg.TextContent()
+	matches := toolCallPattern.FindAllStringSubmatchIndex(text, -1)
+	if len(matches) == 0 {
+		return msg
+	}
+
+	var (
+		calls []agentcore.ToolCall
+		rest  strings.Builder
+		last  int
+	)
+	for _, m := range matches {
+		var parsed struct {
+			Name      string          `json:"name"`
+			Arguments json.RawMessage `json:"arguments"`
+		}
+		if err := json.Unmarshal([]byte(text[m[2]:m[3]]), &parsed); err != nil || parsed.Name == "" {
+			continue // leave malformed blocks in the text
+		}
+		args := parsed.Arguments
+		if len(args) == 0 || string(args) == "null" {
+			args = json.RawMessage("{}")
+		}
+		// Some models double-encode arguments as a JSON string. A string that
+		// doesn't hold JSON stays quoted so Args remains valid JSON.
+		var encoded string
+		if json.Unmarshal(args, &encoded) == nil && json.Valid([]byte(encoded)) {
+			args = json.RawMessage(encoded)
+		}
+		calls = append(calls, agentcore.ToolCall{
+			ID:   newToolCallID(),
+			Name: parsed.Name,
+			Args: args,
+		})
+		rest.WriteString(text[last:m[0]])
+		last = m[1]
+	}
+	if len(calls) == 0 {
+		return msg
+	}
+	rest.WriteString(text[last:])
+
+	content := make([]agentcore.ContentBlock, 0, len(msg.Content)+len(calls))
+	for _, b := range msg.Content {
+		if b.Type != agentcore.ContentText {
+			content = append(content, b)
+		}
+	}
+	if remaining := strings.TrimSpace(rest.String()); remaining != "" {
+		content = append(content, agentcore.TextBlock(remaining))
+	}
+	for _, c := range calls {
+		content = append(content, agentcore.ToolCallBlock(c))
+	}
+	msg.Content = content
+	msg.StopReason = agentcore.StopReasonToolUse
+	return msg
+}
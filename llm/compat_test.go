@@ -0,0 +1,251 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/voocel/agentcore"
+)
+
+func TestToolCallIDsUnique(t *testing.T) {
+	const n = 10000
+	seen := make(map[string]struct{}, n)
+	for i := range n {
+		id := newToolCallID()
+		if !strings.HasPrefix(id, "call_") {
+			t.Fatalf("id %q lacks call_ prefix", id)
+		}
+		if _, dup := seen[id]; dup {
+			t.Fatalf("duplicate id %q after %d ids", id, i)
+		}
+		seen[id] = struct{}{}
+	}
+}
+
+func TestExtractToolCallsAssignsDistinctIDs(t *testing.T) {
+	var text strings.Builder
+	for i := range 100 {
+		fmt.Fprintf(&text, `<tool_call>{"name":"t%d","arguments":{}}</tool_call>`, i)
+	}
+	msg := ExtractToolCalls(agentcore.Message{
+		Role:    agentcore.RoleAssistant,
+		Content: []agentcore.ContentBlock{agentcore.TextBlock(text.String())},
+	})
+	calls := msg.ToolCalls()
+	if len(calls) != 100 {
+		t.Fatalf("got %d tool calls, want 100", len(calls))
+	}
+	seen := make(map[string]bool)
+	for _, c := range calls {
+		if seen[c.ID] {
+			t.Fatalf("duplicate id %q", c.ID)
+		}
+		seen[c.ID] = true
+	}
+}
+
+func TestSetToolCallIDGenerator(t *testing.T) {
+	n := 0
+	SetToolCallIDGenerator(func() string { n++; return fmt.Sprintf("id-%d", n) })
+	defer SetToolCallIDGenerator(nil)
+
+	if a, b := newToolCallID(), newToolCallID(); a != "id-1" || b != "id-2" {
+		t.Fatalf("got %q, %q; want id-1, id-2", a, b)
+	}
+	SetToolCallIDGenerator(nil)
+	if id := newToolCallID(); !strings.HasPrefix(id, "call_") {
+		t.Fatalf("nil generator did not restore default: %q", id)
+	}
+}
+
+// compatServer fakes an OpenAI-compatible endpoint. Chat requests that offer
+// tools are answered by tools: "call" replies with a tool call, "ignore"
+// with plain text, and "reject" with HTTP 400.
+func compatServer(t *testing.T, tools string, block <-chan struct{}) *OpenAICompatibleModel {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if block != nil {
+			<-block
+		}
+		var req struct {
+			Stream bool              `json:"stream"`
+			Tools  []json.RawMessage `json:"tools"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+
+		if req.Stream {
+			w.Header().Set("Content-Type", "text/event-stream")
+			fmt.Fprint(w, `data: {"id":"1","model":"m","choices":[{"index":0,"delta":{"content":"ok"}}]}`+"\n\n")
+			fmt.Fprint(w, "data: [DONE]\n\n")
+			return
+		}
+		msg := map[string]any{"role": "assistant", "content": "ok"}
+		if len(req.Tools) > 0 {
+			switch tools {
+			case "reject":
+				http.Error(w, `{"error":{"message":"tools not supported"}}`, http.StatusBadRequest)
+				return
+			case "call":
+				msg = map[string]any{"role": "assistant", "tool_calls": []map[string]any{{
+					"id": "c1", "type": "function",
+					"function": map[string]any{"name": "noop", "arguments": "{}"},
+				}}}
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{
+			"id": "1", "model": "m",
+			"choices": []map[string]any{{"index": 0, "message": msg, "finish_reason": "stop"}},
+		})
+	}))
+	t.Cleanup(srv.Close)
+
+	model, err := NewOpenAICompatibleProvider("m", srv.URL, "", Capabilities{SupportsJSONMode: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return model
+}
+
+func TestProbeCapabilities(t *testing.T) {
+	tests := []struct {
+		tools string
+		want  bool
+	}{
+		{"call", true},
+		{"ignore", false},
+		{"reject", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tools, func(t *testing.T) {
+			model := compatServer(t, tt.tools, nil)
+			caps, err := model.ProbeCapabilities(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if caps.SupportsTools != tt.want || !caps.SupportsStreaming || !caps.SupportsJSONMode {
+				t.Fatalf("caps = %+v, want tools=%v, streaming and declared JSON mode", caps, tt.want)
+			}
+			if model.Capabilities() != caps {
+				t.Fatalf("cached caps = %+v, want %+v", model.Capabilities(), caps)
+			}
+		})
+	}
+}
+
+func TestProbeCapabilitiesDoesNotBlockReaders(t *testing.T) {
+	release := make(chan struct{})
+	model := compatServer(t, "call", release)
+	var once sync.Once
+	unblock := func() { once.Do(func() { close(release) }) }
+	t.Cleanup(unblock) // runs before the server closes
+
+	probed := make(chan error, 1)
+	go func() {
+		_, err := model.ProbeCapabilities(context.Background())
+		probed <- err
+	}()
+
+	read := make(chan Capabilities, 1)
+	go func() {
+		time.Sleep(10 * time.Millisecond) // let the probe reach the server
+		read <- model.Capabilities()
+	}()
+	select {
+	case <-read:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Capabilities blocked while a probe was in flight")
+	}
+
+	unblock()
+	if err := <-probed; err != nil {
+		t.Fatal(err)
+	}
+	if !model.Capabilities().SupportsTools {
+		t.Fatalf("caps = %+v, want the probe result stored", model.Capabilities())
+	}
+}
+
+func TestExtractToolCalls(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		calls []string // "name args" per parsed call
+		rest  string   // remaining text
+	}{
+		{"object args", `<tool_call>{"name":"ls","arguments":{"path":"."}}</tool_call>`, []string{`ls {"path":"."}`}, ""},
+		{"double encoded", `<tool_call>{"name":"ls","arguments":"{\"path\":\".\"}"}</tool_call>`, []string{`ls {"path":"."}`}, ""},
+		{"encoded non-JSON", `<tool_call>{"name":"echo","arguments":"hello {"}</tool_call>`, []string{`echo "hello {"`}, ""},
+		{"missing args", `<tool_call>{"name":"now"}</tool_call>`, []string{`now {}`}, ""},
+		{"null args", `<tool_call>{"name":"now","arguments":null}</tool_call>`, []string{`now {}`}, ""},
+		{"surrounding text", "Let me look.\n<tool_call>\n{\"name\":\"ls\",\"arguments\":{}}\n</tool_call>\nDone.", []string{`ls {}`}, "Let me look.\n\nDone."},
+		{"two calls", `<tool_call>{"name":"a","arguments":{}}</tool_call><tool_call>{"name":"b","arguments":{"n":1}}</tool_call>`, []string{`a {}`, `b {"n":1}`}, ""},
+		{"malformed kept", `<tool_call>{"name":</tool_call><tool_call>{"name":"a","arguments":{}}</tool_call>`, []string{`a {}`}, `<tool_call>{"name":</tool_call>`},
+		{"no calls", "just text", nil, "just text"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg := ExtractToolCalls(agentcore.Message{
+				Role:    agentcore.RoleAssistant,
+				Content: []agentcore.ContentBlock{agentcore.TextBlock(tt.text)},
+			})
+			var got []string
+			for _, c := range msg.ToolCalls() {
+				if !json.Valid(c.Args) {
+					t.Fatalf("call %s has invalid args %q", c.Name, c.Args)
+				}
+				got = append(got, c.Name+" "+string(c.Args))
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.calls) {
+				t.Fatalf("calls = %q, want %q", got, tt.calls)
+			}
+			if msg.TextContent() != tt.rest {
+				t.Fatalf("text = %q, want %q", msg.TextContent(), tt.rest)
+			}
+			if len(tt.calls) > 0 && msg.StopReason != agentcore.StopReasonToolUse {
+				t.Fatalf("stop reason = %q, want tool use", msg.StopReason)
+			}
+		})
+	}
+}
+
+func TestInjectToolPromptRoundTrip(t *testing.T) {
+	call := agentcore.ToolCall{ID: "c1", Name: "read", Args: json.RawMessage(`{"path":"a.txt"}`)}
+	tools := []ToolSpec{{Name: "read", Description: "Read a file", Parameters: map[string]any{"type": "object"}}}
+	msgs := InjectToolPrompt([]Message{
+		agentcore.SystemMsg("Be brief."),
+		agentcore.UserMsg("show a.txt"),
+		{Role: agentcore.RoleAssistant, Content: []agentcore.ContentBlock{agentcore.ToolCallBlock(call)}},
+		agentcore.ToolResultMsg("c1", json.RawMessage(`"hello"`), false),
+	}, tools)
+
+	if len(msgs) != 4 {
+		t.Fatalf("got %d messages, want 4", len(msgs))
+	}
+	sys := msgs[0].TextContent()
+	if msgs[0].Role != agentcore.RoleSystem || !strings.HasPrefix(sys, "Be brief.") || !strings.Contains(sys, "- read: Read a file") {
+		t.Fatalf("system prompt = %q, want the original prompt plus the tool list", sys)
+	}
+	if msgs[2].HasToolCalls() {
+		t.Fatal("assistant tool call was not rendered as text")
+	}
+	if r := msgs[3]; r.Role != agentcore.RoleUser || !strings.Contains(r.TextContent(), `<tool_result id="c1">`) {
+		t.Fatalf("tool result = %+v, want a <tool_result> user message", r)
+	}
+
+	parsed := ExtractToolCalls(msgs[2]).ToolCalls()
+	if len(parsed) != 1 || parsed[0].Name != call.Name || string(parsed[0].Args) != string(call.Args) {
+		t.Fatalf("round trip = %+v, want %s %s", parsed, call.Name, call.Args)
+	}
+
+	if got := InjectToolPrompt([]Message{agentcore.UserMsg("hi")}, tools); len(got) != 2 || got[0].Role != agentcore.RoleSystem {
+		t.Fatalf("without a system prompt got %+v, want one prepended", got)
+	}
+}
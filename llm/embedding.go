@@ -0,0 +1,113 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+const defaultOpenAIBaseURL = "https://api.openai.com/v1"
+
+// OpenAIEmbedder calls the OpenAI /embeddings endpoint.
+// Works with any OpenAI-compatible server (Ollama, vLLM) via baseURL.
+// Implements memory.Embedder.
+type OpenAIEmbedder struct {
+	model   string
+	apiKey  string
+	baseURL string
+	client  *http.Client
+}
+
+// NewOpenAIEmbedder creates an embedder for the given model
+// (e.g. "text-embedding-3-small").
+func NewOpenAIEmbedder(model, apiKey string, baseURL ...string) *OpenAIEmbedder {
+	url := defaultOpenAIBaseURL
+	if len(baseURL) > 0 && baseURL[0] != "" {
+		url = strings.TrimRight(baseURL[0], "/")
+	}
+	return &OpenAIEmbedder{model: model, apiKey: apiKey, baseURL: url, client: http.DefaultClient}
+}
+
+type embeddingRequest struct {
+	Model string   `json:"model"`
+	Input []string `json:"input"`
+}
+
+type embeddingResponse struct {
+	Data []struct {
+		Index     int       `json:"index"`
+		Embedding []float32 `json:"embedding"`
+	} `json:"data"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+}
+
+// Embed returns one vector per input text, in input order.
+func (e *OpenAIEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	if len(texts) == 0 {
+		return nil, nil
+	}
+	body, err := json.Marshal(embeddingRequest{Model: e.model, Input: texts})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.baseURL+"/embeddings", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if e.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+e.apiKey)
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("llm: embeddings request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("llm: read embeddings response: %w", err)
+	}
+
+	var parsed embeddingResponse
+	if err := json.Unmarshal(data, &parsed); err != nil {
+		return nil, fmt.Errorf("llm: embeddings status %d: %s", resp.StatusCode, truncateForError(data))
+	}
+	if parsed.Error != nil {
+		return nil, fmt.Errorf("llm: embeddings status %d: %s", resp.StatusCode, parsed.Error.Message)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("llm: embeddings status %d: %s", resp.StatusCode, truncateForError(data))
+	}
+
+	out := make([][]float32, len(texts))
+	for _, d := range parsed.Data {
+		if d.Index >= 0 && d.Index < len(out) {
+			out[d.Index] = d.Embedding
+		}
+	}
+	for i, v := range out {
+		if v == nil {
+			return nil, fmt.Errorf("llm: embeddings response missing vector for input %d", i)
+		}
+	}
+	return out, nil
+}
+
+// truncateForError shortens a response body for inclusion in an error message.
+func truncateForError(b []byte) string {
+	const maxLen = 300
+	s := strings.TrimSpace(string(b))
+	if len(s) > maxLen {
+		return s[:maxLen] + "..."
+	}
+	return s
+}
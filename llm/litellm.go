@@ -2,6 +2,7 @@ package llm
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 
@@ -9,6 +10,10 @@ import (
 	"github.com/voocel/litellm"
 )
 
+// ErrImagesUnsupported is returned when a request carries image content but
+// the model does not declare CapabilityMultimodal.
+var ErrImagesUnsupported = errors.New("model does not support image input")
+
 // LiteLLMAdapter adapts litellm to the llm.ChatModel interface.
 type LiteLLMAdapter struct {
 	*BaseModel
@@ -17,6 +22,8 @@ type LiteLLMAdapter struct {
 }
 
 // NewLiteLLMAdapter creates an adapter from a litellm Client.
+// CapabilityMultimodal is declared only for models the litellm registry lists
+// with vision support; enable it for other models with SetCapability.
 func NewLiteLLMAdapter(model string, client *litellm.Client) *LiteLLMAdapter {
 	modelInfo := ModelInfo{
 		Name:     model,
@@ -29,12 +36,17 @@ func NewLiteLLMAdapter(model string, client *litellm.Client) *LiteLLMAdapter {
 		},
 	}
 
-	// Enrich from registry if available
+	// Enrich from registry if available (custom pricing wins)
 	if caps, ok := litellm.GetModelCapabilities(model); ok {
 		modelInfo.MaxTokens = caps.MaxOutputTokens
 		modelInfo.ContextSize = caps.MaxInputTokens
+		if caps.SupportsVision {
+			modelInfo.Capabilities = append(modelInfo.Capabilities, string(CapabilityMultimodal))
+		}
 	}
-	if p, ok := litellm.GetModelPricing(model); ok {
+	if p, ok := registeredPricing(model); ok {
+		modelInfo.Pricing = &p
+	} else if p, ok := litellm.GetModelPricing(model); ok {
 		modelInfo.Pricing = &ModelPricing{
 			InputPerToken:  p.InputCostPerToken,
 			OutputPerToken: p.OutputCostPerToken,
@@ -84,6 +96,9 @@ func (l *LiteLLMAdapter) ProviderName() string {
 
 // Generate produces a synchronous response.
 func (l *LiteLLMAdapter) Generate(ctx context.Context, messages []Message, tools []ToolSpec, opts ...CallOption) (*LLMResponse, error) {
+	if err := l.checkImages(messages); err != nil {
+		return nil, err
+	}
 	cfg := l.GetConfig()
 	llmMessages := convertMessages(messages)
 
@@ -111,6 +126,9 @@ func (l *LiteLLMAdapter) Generate(ctx context.Context, messages []Message, tools
 
 // GenerateStream produces a streaming response with fine-grained events.
 func (l *LiteLLMAdapter) GenerateStream(ctx context.Context, messages []Message, tools []ToolSpec, opts ...CallOption) (<-chan StreamEvent, error) {
+	if err := l.checkImages(messages); err != nil {
+		return nil, err
+	}
 	cfg := l.GetConfig()
 	llmMessages := convertMessages(messages)
 
@@ -301,6 +319,20 @@ func (l *LiteLLMAdapter) GenerateStream(ctx context.Context, messages []Message,
 	return eventChan, nil
 }
 
+// checkImages rejects image content for models without multimodal support,
+// rather than letting it be silently dropped by the provider.
+func (l *LiteLLMAdapter) checkImages(messages []Message) error {
+	if l.SupportsCapability(CapabilityMultimodal) {
+		return nil
+	}
+	for _, msg := range messages {
+		if hasImageContent(msg.Content) {
+			return fmt.Errorf("llm: %s: %w", l.model, ErrImagesUnsupported)
+		}
+	}
+	return nil
+}
+
 // lastBlockIndex returns the index of the last ContentBlock of the given type.
 func lastBlockIndex(blocks []agentcore.ContentBlock, ct agentcore.ContentType) int {
 	for i := len(blocks) - 1; i >= 0; i-- {
@@ -444,8 +476,11 @@ func mapStopReason(reason string) agentcore.StopReason {
 	}
 }
 
-// applyCallConfig resolves CallOptions once and applies API key, thinking, and
-// session config to the litellm request.
+// applyCallConfig resolves CallOptions once and applies API key, thinking,
+// session, and response format config to the litellm request.
+//
+// CallConfig.Seed is not sent: litellm has no seed field and its providers
+// reject unknown extra parameters, so passing it would fail every call.
 func applyCallConfig(req *litellm.Request, opts []CallOption) {
 	callCfg := agentcore.ResolveCallConfig(opts)
 
@@ -472,6 +507,14 @@ func applyCallConfig(req *litellm.Request, opts []CallOption) {
 		}
 		req.Extra["session_id"] = callCfg.SessionID
 	}
+
+	// Native structured output
+	if callCfg.ResponseSchema != nil {
+		req.ResponseFormat = &litellm.ResponseFormat{
+			Type:       litellm.ResponseFormatJSONSchema,
+			JSONSchema: &litellm.JSONSchema{Name: "response", Schema: callCfg.ResponseSchema},
+		}
+	}
 }
 
 func applyToolConfig(request *litellm.Request, tools []ToolSpec) {
@@ -0,0 +1,104 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/voocel/agentcore"
+)
+
+// openAIServer answers every chat completion with content and records the
+// decoded request bodies.
+func openAIServer(t *testing.T, content string) (*LiteLLMAdapter, func() []map[string]any) {
+	t.Helper()
+	var (
+		mu     sync.Mutex
+		bodies []map[string]any
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		mu.Lock()
+		bodies = append(bodies, body)
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{
+			"id":    "chatcmpl-1",
+			"model": "gpt-4o",
+			"choices": []map[string]any{{
+				"index":         0,
+				"message":       map[string]any{"role": "assistant", "content": content},
+				"finish_reason": "stop",
+			}},
+			"usage": map[string]any{"prompt_tokens": 3, "completion_tokens": 2, "total_tokens": 5},
+		})
+	}))
+	t.Cleanup(srv.Close)
+
+	model, err := NewOpenAIModel("gpt-4o", "test-key", srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return model, func() []map[string]any {
+		mu.Lock()
+		defer mu.Unlock()
+		return bodies
+	}
+}
+
+func TestLiteLLMResponseSchema(t *testing.T) {
+	model, bodies := openAIServer(t, `{"city":"Paris"}`)
+	schema := map[string]any{
+		"type":       "object",
+		"properties": map[string]any{"city": map[string]any{"type": "string"}},
+		"required":   []any{"city"},
+	}
+
+	var out struct{ City string }
+	raw, err := agentcore.GenerateJSON(context.Background(), model,
+		[]Message{agentcore.UserMsg("Where is the Louvre?")}, schema, &out)
+	if err != nil {
+		t.Fatalf("GenerateJSON: %v (raw %q)", err, raw)
+	}
+	if out.City != "Paris" {
+		t.Fatalf("city = %q, want Paris", out.City)
+	}
+
+	body := bodies()[0]
+	if _, ok := body["extra"]; ok {
+		t.Errorf("request carries extra parameters: %v", body["extra"])
+	}
+	format, _ := body["response_format"].(map[string]any)
+	if format["type"] != "json_schema" {
+		t.Fatalf("response_format = %v, want json_schema", body["response_format"])
+	}
+	js, _ := format["json_schema"].(map[string]any)
+	props, _ := js["schema"].(map[string]any)["properties"].(map[string]any)
+	if js["name"] != "response" || props["city"] == nil {
+		t.Fatalf("json_schema = %v, want the caller's schema", js)
+	}
+}
+
+func TestLiteLLMIgnoresSeed(t *testing.T) {
+	model, bodies := openAIServer(t, "ok")
+
+	resp, err := model.Generate(context.Background(),
+		[]Message{agentcore.UserMsg("hi")}, nil, agentcore.WithCallSeed(42))
+	if err != nil {
+		t.Fatalf("Generate with seed: %v", err)
+	}
+	if resp.Message.TextContent() != "ok" {
+		t.Fatalf("reply = %q, want ok", resp.Message.TextContent())
+	}
+	body := bodies()[0]
+	if _, ok := body["extra"]; ok {
+		t.Errorf("request carries extra parameters: %v", body["extra"])
+	}
+}
@@ -0,0 +1,187 @@
+// Package llmtest provides a scripted ChatModel and a recording Tool for
+// testing agent logic without network access.
+//
+// Usage:
+//
+//	model := llmtest.NewModel(
+//	    llmtest.ToolCalls(agentcore.ToolCall{ID: "1", Name: "lookup", Args: json.RawMessage(`{"q":"go"}`)}),
+//	    llmtest.Text("Go is a programming language."),
+//	)
+//	lookup := llmtest.NewTool("lookup", func(ctx context.Context, args json.RawMessage) (json.RawMessage, error) {
+//	    return json.RawMessage(`"a language"`), nil
+//	})
+//	agent := agentcore.NewAgent(agentcore.WithModel(model), agentcore.WithTools(lookup))
+//	_ = agent.Prompt("what is go?")
+//	agent.WaitForIdle()
+//
+//	if len(lookup.Calls()) != 1 || model.Remaining() != 0 { t.Fatal("unexpected flow") }
+package llmtest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+
+	"github.com/voocel/agentcore"
+)
+
+// ErrNoResponse is returned when the model is called more times than it has
+// scripted responses.
+var ErrNoResponse = errors.New("llmtest: no scripted response left")
+
+// Response is one scripted model turn: a message, or an error to return.
+type Response struct {
+	Message agentcore.Message
+	Err     error
+}
+
+// Text scripts a plain assistant reply.
+func Text(text string) Response {
+	return Response{Message: agentcore.Message{
+		Role:       agentcore.RoleAssistant,
+		Content:    []agentcore.ContentBlock{agentcore.TextBlock(text)},
+		StopReason: agentcore.StopReasonStop,
+	}}
+}
+
+// ToolCalls scripts an assistant turn that requests the given tool calls.
+func ToolCalls(calls ...agentcore.ToolCall) Response {
+	msg := agentcore.Message{Role: agentcore.RoleAssistant, StopReason: agentcore.StopReasonToolUse}
+	for _, c := range calls {
+		msg.Content = append(msg.Content, agentcore.ToolCallBlock(c))
+	}
+	return Response{Message: msg}
+}
+
+// Error scripts a failed model call.
+func Error(err error) Response { return Response{Err: err} }
+
+// Call is a recorded model invocation.
+type Call struct {
+	Messages []agentcore.Message
+	Tools    []agentcore.ToolSpec
+	Config   agentcore.CallConfig
+}
+
+// Model is a ChatModel that returns scripted responses in order and records
+// every request. Safe for concurrent use.
+type Model struct {
+	mu        sync.Mutex
+	responses []Response
+	calls     []Call
+}
+
+// NewModel creates a model that replies with responses in order.
+func NewModel(responses ...Response) *Model {
+	return &Model{responses: responses}
+}
+
+// Add appends more scripted responses.
+func (m *Model) Add(responses ...Response) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.responses = append(m.responses, responses...)
+}
+
+// Calls returns the requests received so far.
+func (m *Model) Calls() []Call {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Call(nil), m.calls...)
+}
+
+// Remaining returns how many scripted responses have not been consumed.
+func (m *Model) Remaining() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.responses)
+}
+
+func (m *Model) SupportsTools() bool { return true }
+
+func (m *Model) next(messages []agentcore.Message, tools []agentcore.ToolSpec, opts []agentcore.CallOption) (agentcore.Message, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls = append(m.calls, Call{
+		Messages: append([]agentcore.Message(nil), messages...),
+		Tools:    append([]agentcore.ToolSpec(nil), tools...),
+		Config:   agentcore.ResolveCallConfig(opts),
+	})
+	if len(m.responses) == 0 {
+		return agentcore.Message{}, ErrNoResponse
+	}
+	r := m.responses[0]
+	m.responses = m.responses[1:]
+	return r.Message, r.Err
+}
+
+func (m *Model) Generate(ctx context.Context, messages []agentcore.Message, tools []agentcore.ToolSpec, opts ...agentcore.CallOption) (*agentcore.LLMResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	msg, err := m.next(messages, tools, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &agentcore.LLMResponse{Message: msg}, nil
+}
+
+// GenerateStream streams the next scripted message. A scripted error arrives
+// as a StreamEventError: failing to open the stream would make the loop fall
+// back to Generate, which would consume the next response.
+func (m *Model) GenerateStream(ctx context.Context, messages []agentcore.Message, tools []agentcore.ToolSpec, opts ...agentcore.CallOption) (<-chan agentcore.StreamEvent, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	msg, err := m.next(messages, tools, opts)
+	if err != nil {
+		ch := make(chan agentcore.StreamEvent, 1)
+		ch <- agentcore.StreamEvent{Type: agentcore.StreamEventError, Err: err}
+		close(ch)
+		return ch, nil
+	}
+	return agentcore.StreamMessage(msg), nil
+}
+
+// Tool is an agentcore.Tool backed by a function that records its calls.
+type Tool struct {
+	name   string
+	fn     func(ctx context.Context, args json.RawMessage) (json.RawMessage, error)
+	schema map[string]any
+
+	mu    sync.Mutex
+	calls []json.RawMessage
+}
+
+// NewTool creates a tool named name that runs fn. A nil fn returns null.
+func NewTool(name string, fn func(ctx context.Context, args json.RawMessage) (json.RawMessage, error)) *Tool {
+	return &Tool{name: name, fn: fn, schema: map[string]any{"type": "object"}}
+}
+
+// WithSchema sets the input schema the tool advertises (default: any object).
+func (t *Tool) WithSchema(schema map[string]any) *Tool {
+	t.schema = schema
+	return t
+}
+
+// Calls returns the arguments of every invocation so far.
+func (t *Tool) Calls() []json.RawMessage {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]json.RawMessage(nil), t.calls...)
+}
+
+func (t *Tool) Name() string           { return t.name }
+func (t *Tool) Description() string    { return "test tool " + t.name }
+func (t *Tool) Schema() map[string]any { return t.schema }
+
+func (t *Tool) Execute(ctx context.Context, args json.RawMessage) (json.RawMessage, error) {
+	t.mu.Lock()
+	t.calls = append(t.calls, append(json.RawMessage(nil), args...))
+	t.mu.Unlock()
+	if t.fn == nil {
+		return json.RawMessage("null"), nil
+	}
+	return t.fn(ctx, args)
+}
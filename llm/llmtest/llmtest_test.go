@@ -0,0 +1,34 @@
+package llmtest_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/voocel/agentcore"
+	"github.com/voocel/agentcore/llm/llmtest"
+)
+
+func TestErrorReachesAgent(t *testing.T) {
+	boom := errors.New("boom")
+	model := llmtest.NewModel(llmtest.Error(boom), llmtest.Text("second"))
+	agent := agentcore.NewAgent(agentcore.WithModel(model), agentcore.WithMaxRetries(0))
+
+	var runErr error
+	unsubscribe := agent.Subscribe(func(ev agentcore.Event) {
+		if ev.Type == agentcore.EventError {
+			runErr = ev.Err
+		}
+	})
+	defer unsubscribe()
+	if err := agent.Prompt("hi"); err != nil {
+		t.Fatal(err)
+	}
+	agent.WaitForIdle()
+
+	if !errors.Is(runErr, boom) {
+		t.Fatalf("run error = %v, want the scripted error", runErr)
+	}
+	if len(model.Calls()) != 1 || model.Remaining() != 1 {
+		t.Fatalf("calls = %d, remaining = %d; want only the error consumed", len(model.Calls()), model.Remaining())
+	}
+}
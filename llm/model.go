@@ -1,6 +1,10 @@
 package llm
 
-import "github.com/voocel/agentcore"
+import (
+	"sync"
+
+	"github.com/voocel/agentcore"
+)
 
 // ModelPricing defines per-token cost rates in USD.
 // Set rates to 0 for categories that don't apply.
@@ -11,6 +15,34 @@ type ModelPricing struct {
 	CacheWritePerToken float64 `json:"cache_write_per_token"`
 }
 
+var (
+	pricingMu     sync.RWMutex
+	customPricing = make(map[string]ModelPricing)
+)
+
+// RegisterPricing sets the per-token pricing for a model name, taking
+// precedence over the built-in table. Use it for self-hosted, fine-tuned, or
+// newly released models. Register before constructing the model.
+//
+// Usage:
+//
+//	llm.RegisterPricing("my-finetune", llm.ModelPricing{
+//	    InputPerToken:  0.5 / 1e6,
+//	    OutputPerToken: 1.5 / 1e6,
+//	})
+func RegisterPricing(model string, pricing ModelPricing) {
+	pricingMu.Lock()
+	defer pricingMu.Unlock()
+	customPricing[model] = pricing
+}
+
+func registeredPricing(model string) (ModelPricing, bool) {
+	pricingMu.RLock()
+	defer pricingMu.RUnlock()
+	p, ok := customPricing[model]
+	return p, ok
+}
+
 // CalculateCost computes the monetary cost from pricing rates and token usage.
 // Returns nil if pricing or usage is nil.
 func CalculateCost(pricing *ModelPricing, usage *agentcore.Usage) *agentcore.Cost {
@@ -74,7 +106,9 @@ var DefaultGenerationConfig = &GenerationConfig{
 }
 
 // BaseModel provides common model metadata and capability checks.
+// It is safe for concurrent use.
 type BaseModel struct {
+	mu     sync.RWMutex
 	info   ModelInfo
 	config *GenerationConfig
 }
@@ -86,10 +120,17 @@ func NewBaseModel(info ModelInfo, config *GenerationConfig) *BaseModel {
 	return &BaseModel{info: info, config: config}
 }
 
-func (m *BaseModel) Info() ModelInfo              { return m.info }
+func (m *BaseModel) Info() ModelInfo {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.info
+}
+
 func (m *BaseModel) GetConfig() *GenerationConfig { return m.config }
 
 func (m *BaseModel) SupportsCapability(capability ModelCapability) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
 	for _, c := range m.info.Capabilities {
 		if c == string(capability) {
 			return true
@@ -98,6 +139,24 @@ func (m *BaseModel) SupportsCapability(capability ModelCapability) bool {
 	return false
 }
 
+// SetCapability adds or removes a capability from the model info.
+// The capability list is replaced, never modified in place, so slices
+// returned by Info stay valid.
+func (m *BaseModel) SetCapability(capability ModelCapability, enabled bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	caps := make([]string, 0, len(m.info.Capabilities)+1)
+	for _, c := range m.info.Capabilities {
+		if c != string(capability) {
+			caps = append(caps, c)
+		}
+	}
+	if enabled {
+		caps = append(caps, string(capability))
+	}
+	m.info.Capabilities = caps
+}
+
 func (m *BaseModel) SupportsTools() bool {
 	return m.SupportsCapability(CapabilityToolCalling) || m.SupportsCapability(CapabilityFunctionCall)
 }
@@ -0,0 +1,33 @@
+package llm
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestBaseModelSetCapabilityConcurrent(t *testing.T) {
+	m := NewBaseModel(ModelInfo{Name: "m", Capabilities: []string{string(CapabilityChat)}}, nil)
+
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			m.SetCapability(CapabilityMultimodal, i%2 == 0)
+		}()
+		go func() {
+			defer wg.Done()
+			_ = m.SupportsCapability(CapabilityMultimodal)
+			_ = m.Info().Capabilities
+		}()
+	}
+	wg.Wait()
+
+	if !m.SupportsCapability(CapabilityChat) {
+		t.Fatal("unrelated capability lost")
+	}
+	m.SetCapability(CapabilityMultimodal, false)
+	if m.SupportsCapability(CapabilityMultimodal) {
+		t.Fatal("capability not removed")
+	}
+}
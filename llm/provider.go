@@ -0,0 +1,103 @@
+package llm
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ProviderFactory builds a ChatModel for a model name served by one provider.
+type ProviderFactory func(model, apiKey string, baseURL ...string) (ChatModel, error)
+
+var (
+	providersMu sync.RWMutex
+	providers   = make(map[string]ProviderFactory) // provider name -> factory
+	prefixes    = make(map[string]string)          // model prefix -> provider name
+)
+
+func init() {
+	RegisterProvider("openai", adapterFactory(NewOpenAIModel), "gpt-", "chatgpt-", "o1", "o3", "o4")
+	RegisterProvider("anthropic", adapterFactory(NewAnthropicModel), "claude-")
+	RegisterProvider("gemini", adapterFactory(NewGeminiModel), "gemini-")
+}
+
+// adapterFactory adapts a LiteLLMAdapter constructor, avoiding a non-nil
+// ChatModel holding a nil pointer on error.
+func adapterFactory(fn func(model, apiKey string, baseURL ...string) (*LiteLLMAdapter, error)) ProviderFactory {
+	return func(model, apiKey string, baseURL ...string) (ChatModel, error) {
+		m, err := fn(model, apiKey, baseURL...)
+		if err != nil {
+			return nil, err
+		}
+		return m, nil
+	}
+}
+
+// RegisterProvider makes a provider available to NewModel, both by explicit
+// "name:model" scheme and for model names starting with any of prefixes.
+// Registering an existing name or prefix replaces it.
+//
+// Usage:
+//
+//	llm.RegisterProvider("deepseek", func(model, apiKey string, baseURL ...string) (llm.ChatModel, error) {
+//	    return llm.NewOpenAIModel(model, apiKey, "https://api.deepseek.com/v1")
+//	}, "deepseek-")
+func RegisterProvider(name string, factory ProviderFactory, modelPrefixes ...string) {
+	providersMu.Lock()
+	defer providersMu.Unlock()
+	providers[name] = factory
+	for _, p := range modelPrefixes {
+		prefixes[p] = name
+	}
+}
+
+// NewModel creates a ChatModel, choosing the provider from the model name.
+// An explicit "provider:model" scheme (e.g. "anthropic:my-proxy-model") wins;
+// otherwise the longest registered prefix matches (claude-* → anthropic,
+// gemini-* → gemini, gpt-*/o1/o3/o4 → openai).
+//
+// Usage:
+//
+//	model, err := llm.NewModel("claude-sonnet-4-5", os.Getenv("ANTHROPIC_API_KEY"))
+func NewModel(model, apiKey string, baseURL ...string) (ChatModel, error) {
+	providersMu.RLock()
+	factory, name := resolveProvider(model)
+	if factory == nil {
+		names, prefixList := sortedKeys(providers), sortedKeys(prefixes)
+		providersMu.RUnlock()
+		return nil, fmt.Errorf("no provider registered for model %q (providers: %s; prefixes: %s)",
+			model, strings.Join(names, ", "), strings.Join(prefixList, ", "))
+	}
+	providersMu.RUnlock()
+	return factory(name, apiKey, baseURL...)
+}
+
+// resolveProvider returns the factory for model and the model name with any
+// scheme stripped. Callers hold providersMu.
+func resolveProvider(model string) (ProviderFactory, string) {
+	if scheme, rest, ok := strings.Cut(model, ":"); ok {
+		if f, ok := providers[scheme]; ok {
+			return f, rest
+		}
+	}
+	var best string
+	for p := range prefixes {
+		if strings.HasPrefix(model, p) && len(p) > len(best) {
+			best = p
+		}
+	}
+	if best == "" {
+		return nil, model
+	}
+	return providers[prefixes[best]], model
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
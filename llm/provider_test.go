@@ -0,0 +1,103 @@
+package llm
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestNewModelUnknownConcurrentWithRegister(t *testing.T) {
+	var wg sync.WaitGroup
+	for i := range 20 {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			name := fmt.Sprintf("test-provider-%d", i)
+			RegisterProvider(name, func(model, apiKey string, baseURL ...string) (ChatModel, error) {
+				return nil, nil
+			}, name+"-")
+		}()
+		go func() {
+			defer wg.Done()
+			if _, err := NewModel("no-such-model", ""); err == nil {
+				t.Error("NewModel(no-such-model) succeeded, want error")
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// resolvedModel records which factory built it and for which model name.
+type resolvedModel struct {
+	ChatModel
+	provider, model string
+}
+
+func TestNewModelResolution(t *testing.T) {
+	factory := func(provider string) ProviderFactory {
+		return func(model, apiKey string, baseURL ...string) (ChatModel, error) {
+			return resolvedModel{provider: provider, model: model}, nil
+		}
+	}
+	RegisterProvider("resolve-short", factory("resolve-short"), "rs-")
+	RegisterProvider("resolve-long", factory("resolve-long"), "rs-long-")
+
+	tests := []struct {
+		input    string
+		provider string
+		model    string
+	}{
+		{"rs-1", "resolve-short", "rs-1"},
+		{"rs-", "resolve-short", "rs-"},
+		{"rs-long-1", "resolve-long", "rs-long-1"},
+		{"rs-lon", "resolve-short", "rs-lon"},
+		{"resolve-long:rs-1", "resolve-long", "rs-1"},
+		{"resolve-short:my-proxy", "resolve-short", "my-proxy"},
+		{"resolve-short:", "resolve-short", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			m, err := NewModel(tt.input, "key")
+			if err != nil {
+				t.Fatalf("NewModel(%q): %v", tt.input, err)
+			}
+			got := m.(resolvedModel)
+			if got.provider != tt.provider || got.model != tt.model {
+				t.Fatalf("NewModel(%q) = %s/%s, want %s/%s", tt.input, got.provider, got.model, tt.provider, tt.model)
+			}
+		})
+	}
+}
+
+func TestNewModelBuiltinPrefixes(t *testing.T) {
+	tests := []struct{ model, provider string }{
+		{"gpt-4o", "openai"},
+		{"o3-mini", "openai"},
+		{"claude-sonnet-4-5", "anthropic"},
+		{"gemini-2.5-pro", "gemini"},
+		{"anthropic:my-proxy-model", "anthropic"},
+	}
+	for _, tt := range tests {
+		m, err := NewModel(tt.model, "key")
+		if err != nil {
+			t.Fatalf("NewModel(%q): %v", tt.model, err)
+		}
+		if got := m.(*LiteLLMAdapter).ProviderName(); got != tt.provider {
+			t.Errorf("NewModel(%q) provider = %q, want %q", tt.model, got, tt.provider)
+		}
+	}
+}
+
+func TestNewModelUnknownProvider(t *testing.T) {
+	for _, model := range []string{"llama-3", "nosuch:gpt-4o", ""} {
+		m, err := NewModel(model, "key")
+		if err == nil {
+			t.Fatalf("NewModel(%q) = %v, want error", model, m)
+		}
+		msg := err.Error()
+		if !strings.Contains(msg, fmt.Sprintf("%q", model)) || !strings.Contains(msg, "anthropic") || !strings.Contains(msg, "claude-") {
+			t.Errorf("NewModel(%q) error %q lacks the model and the registered providers and prefixes", model, msg)
+		}
+	}
+}
@@ -1,10 +1,15 @@
 package agentcore
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"math"
+	"reflect"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/voocel/litellm"
@@ -14,12 +19,22 @@ const defaultMaxTurns = 10
 
 // AgentLoop starts an agent loop with new prompt messages.
 // Prompts are added to context and events are emitted for them.
+// Events carry the run's Trace; a loop started from a tool inherits the
+// caller's TraceID.
 func AgentLoop(ctx context.Context, prompts []AgentMessage, agentCtx AgentContext, config LoopConfig) <-chan Event {
+	ctx, trace := startTrace(ctx)
 	ch := make(chan Event, 128)
 
 	go func() {
 		defer close(ch)
 
+		prompts, err := guardInputs(ctx, config.InputGuards, prompts)
+		if err != nil {
+			emit(ch, Event{Type: EventAgentStart})
+			emitError(ch, err)
+			return
+		}
+
 		newMessages := make([]AgentMessage, len(prompts))
 		copy(newMessages, prompts)
 
@@ -40,12 +55,13 @@ func AgentLoop(ctx context.Context, prompts []AgentMessage, agentCtx AgentContex
 		runLoop(ctx, &currentCtx, &newMessages, config, ch)
 	}()
 
-	return ch
+	return traceEvents(ch, trace)
 }
 
 // AgentLoopContinue continues from existing context without adding new messages.
 // The last message in context must convert to user or tool role via ConvertToLLM.
 func AgentLoopContinue(ctx context.Context, agentCtx AgentContext, config LoopConfig) <-chan Event {
+	ctx, trace := startTrace(ctx)
 	ch := make(chan Event, 128)
 
 	if len(agentCtx.Messages) == 0 {
@@ -53,7 +69,7 @@ func AgentLoopContinue(ctx context.Context, agentCtx AgentContext, config LoopCo
 			defer close(ch)
 			emitError(ch, fmt.Errorf("cannot continue: no messages in context"))
 		}()
-		return ch
+		return traceEvents(ch, trace)
 	}
 
 	go func() {
@@ -72,7 +88,7 @@ func AgentLoopContinue(ctx context.Context, agentCtx AgentContext, config LoopCo
 		runLoop(ctx, &currentCtx, &newMessages, config, ch)
 	}()
 
-	return ch
+	return traceEvents(ch, trace)
 }
 
 // runLoop is the main double-loop logic shared by AgentLoop and AgentLoopContinue.
@@ -82,6 +98,8 @@ func runLoop(ctx context.Context, currentCtx *AgentContext, newMessages *[]Agent
 		maxTurns = defaultMaxTurns
 	}
 
+	ctx, budget := withBudgetTracker(ctx, config.Budget)
+
 	firstTurn := true
 	turnCount := 0
 	toolErrors := make(map[string]int) // consecutive failure count per tool
@@ -120,6 +138,13 @@ func runLoop(ctx context.Context, currentCtx *AgentContext, newMessages *[]Agent
 
 			// Process pending messages (inject before next LLM call)
 			if len(pendingMessages) > 0 {
+				guarded, err := guardInputs(ctx, config.InputGuards, pendingMessages)
+				if err != nil {
+					emit(ch, Event{Type: EventError, Err: err})
+					emit(ch, Event{Type: EventAgentEnd, NewMessages: *newMessages})
+					return
+				}
+				pendingMessages = guarded
 				for _, msg := range pendingMessages {
 					emit(ch, Event{Type: EventMessageStart, Message: msg})
 					emit(ch, Event{Type: EventMessageEnd, Message: msg})
@@ -129,12 +154,25 @@ func runLoop(ctx context.Context, currentCtx *AgentContext, newMessages *[]Agent
 				pendingMessages = nil
 			}
 
+			// Stop before a call that would exceed the budget (or after a tool veto)
+			if err := budget.checkNext(); err != nil {
+				emit(ch, Event{Type: EventError, Err: err})
+				emit(ch, Event{Type: EventAgentEnd, NewMessages: *newMessages})
+				return
+			}
+
 			// Call LLM with retry (streaming: events emitted inside callLLM)
 			assistantMsg, err := callLLMWithRetry(ctx, currentCtx, config, ch)
 			if err != nil {
-				emitError(ch, fmt.Errorf("llm call failed: %w", err))
+				var guardErr *GuardrailError
+				if errors.As(err, &guardErr) {
+					emitError(ch, err)
+				} else {
+					emitError(ch, fmt.Errorf("llm call failed: %w", err))
+				}
 				return
 			}
+			budget.add(assistantMsg.Usage)
 
 			// Check stop reason — terminate early on error/aborted
 			if assistantMsg.StopReason == StopReasonError || assistantMsg.StopReason == StopReasonAborted {
@@ -175,7 +213,8 @@ func runLoop(ctx context.Context, currentCtx *AgentContext, newMessages *[]Agent
 				steeringAfterTools = steering
 			}
 
-			emit(ch, Event{Type: EventTurnEnd, Message: assistantMsg, ToolResults: turnToolResults})
+			runUsage := budget.snapshot()
+			emit(ch, Event{Type: EventTurnEnd, Message: assistantMsg, ToolResults: turnToolResults, Usage: &runUsage})
 			turnCount++
 
 			// Get steering messages after turn completes
@@ -227,7 +266,7 @@ func callLLMWithRetry(ctx context.Context, agentCtx *AgentContext, config LoopCo
 			return recoverOverflow(ctx, agentCtx, config, ch, err)
 		}
 
-		if !litellm.IsRetryableError(err) || attempt == maxRetries {
+		if !isRetryableError(err) || attempt == maxRetries {
 			return Message{}, err
 		}
 
@@ -279,6 +318,12 @@ func recoverOverflow(ctx context.Context, agentCtx *AgentContext, config LoopCon
 	return callLLM(ctx, agentCtx, config, ch)
 }
 
+// isRetryableError reports whether a failed LLM call is worth retrying:
+// transient provider errors and empty responses.
+func isRetryableError(err error) bool {
+	return litellm.IsRetryableError(err) || errors.Is(err, ErrEmptyResponse)
+}
+
 // retryDelay calculates the wait duration using exponential backoff,
 // capped at 30s. Respects Retry-After from rate limit errors.
 func retryDelay(err error, attempt int) time.Duration {
@@ -320,28 +365,31 @@ func callLLM(ctx context.Context, agentCtx *AgentContext, config LoopConfig, ch
 	// Repair orphaned tool call / result pairs
 	llmMessages = RepairMessageSequence(llmMessages)
 
-	// Build tool specs
-	toolSpecs := buildToolSpecs(agentCtx.Tools)
+	// Build tool specs from the tools allowed for this call
+	tools := filterTools(ctx, agentCtx.Tools, config.ToolFilter)
+	toolSpecs := buildToolSpecs(tools)
 
 	// Prepend system prompt as first message if set
-	if agentCtx.SystemPrompt != "" {
-		llmMessages = append([]Message{SystemMsg(agentCtx.SystemPrompt)}, llmMessages...)
+	systemPrompt := agentCtx.SystemPrompt
+	if config.RenderSystemPrompt != nil {
+		if rendered, err := config.RenderSystemPrompt(tools); err != nil {
+			emit(ch, Event{Type: EventWarning, Err: fmt.Errorf("system prompt template: %w", err)})
+		} else {
+			systemPrompt = rendered
+		}
+	}
+	if systemPrompt != "" {
+		llmMessages = append([]Message{SystemMsg(systemPrompt)}, llmMessages...)
 	}
 
+	req := LLMRequest{Messages: llmMessages, Tools: toolSpecs}
+
 	// Call via StreamFn (non-streaming shortcut, e.g. mock/proxy)
 	if config.StreamFn != nil {
-		resp, err := config.StreamFn(ctx, &LLMRequest{
-			Messages: llmMessages,
-			Tools:    toolSpecs,
-		})
-		if err != nil {
-			return Message{}, err
-		}
-		resp.Message.Timestamp = time.Now()
-		msg := resp.Message
-		emit(ch, Event{Type: EventMessageStart, Message: msg})
-		emit(ch, Event{Type: EventMessageEnd, Message: msg})
-		return msg, nil
+		start := time.Now()
+		msg, err := callStreamFn(ctx, config.StreamFn, &req, config.OutputGuards, ch)
+		logLLMCall(ctx, config.LogRequest, req, nil, msg, err, start)
+		return msg, err
 	}
 
 	if config.Model == nil {
@@ -377,13 +425,41 @@ func callLLM(ctx context.Context, agentCtx *AgentContext, config LoopConfig, ch
 		callOpts = append(callOpts, WithCallSessionID(config.SessionID))
 	}
 
+	// Sampling seed for reproducible runs
+	if config.Seed != nil {
+		callOpts = append(callOpts, WithCallSeed(*config.Seed))
+	}
+
 	// Use streaming for real-time token deltas
-	return callLLMStream(ctx, config.Model, llmMessages, toolSpecs, callOpts, ch)
+	start := time.Now()
+	msg, err := callLLMStream(ctx, config.Model, llmMessages, toolSpecs, callOpts, config.OutputGuards, ch)
+	logLLMCall(ctx, config.LogRequest, req, callOpts, msg, err, start)
+	return msg, err
+}
+
+// callStreamFn calls a StreamFn and emits the response as one message.
+func callStreamFn(ctx context.Context, fn StreamFn, req *LLMRequest, guards []Guard, ch chan<- Event) (Message, error) {
+	resp, err := fn(ctx, req)
+	if err != nil {
+		return Message{}, err
+	}
+	if err := checkEmptyResponse(resp.Message); err != nil {
+		return Message{}, err
+	}
+	resp.Message.Timestamp = time.Now()
+	msg, err := guardMessage(ctx, GuardOutput, guards, resp.Message)
+	if err != nil {
+		return Message{}, err
+	}
+	emit(ch, Event{Type: EventMessageStart, Message: msg})
+	emit(ch, Event{Type: EventMessageEnd, Message: msg})
+	return msg, nil
 }
 
 // callLLMStream uses GenerateStream and emits real-time events.
 // The adapter builds partial Messages with ContentBlocks and emits fine-grained StreamEvents.
-func callLLMStream(ctx context.Context, model ChatModel, messages []Message, tools []ToolSpec, opts []CallOption, ch chan<- Event) (Message, error) {
+// With output guards, events are held until the message completes and is guarded.
+func callLLMStream(ctx context.Context, model ChatModel, messages []Message, tools []ToolSpec, opts []CallOption, guards []Guard, ch chan<- Event) (Message, error) {
 	streamCh, err := model.GenerateStream(ctx, messages, tools, opts...)
 	if err != nil {
 		// Fallback to non-streaming
@@ -391,8 +467,14 @@ func callLLMStream(ctx context.Context, model ChatModel, messages []Message, too
 		if err != nil {
 			return Message{}, err
 		}
+		if err := checkEmptyResponse(resp.Message); err != nil {
+			return Message{}, err
+		}
 		resp.Message.Timestamp = time.Now()
-		return resp.Message, nil
+		return guardMessage(ctx, GuardOutput, guards, resp.Message)
+	}
+	if len(guards) > 0 {
+		return callLLMStreamGuarded(ctx, streamCh, guards, ch)
 	}
 
 	var (
@@ -415,13 +497,16 @@ func callLLMStream(ctx context.Context, model ChatModel, messages []Message, too
 				started = true
 				emit(ch, Event{Type: EventMessageStart, Message: partial})
 			}
-			emit(ch, Event{Type: EventMessageUpdate, Message: partial, Delta: ev.Delta})
+			emit(ch, Event{Type: EventMessageUpdate, Message: partial, Delta: ev.Delta, DeltaType: ev.Type})
 
 		case StreamEventTextEnd, StreamEventThinkingEnd, StreamEventToolCallEnd:
 			partial = ev.Message
 
 		case StreamEventDone:
 			finalMsg := ev.Message
+			if err := checkEmptyResponse(finalMsg); err != nil {
+				return Message{}, err
+			}
 			finalMsg.Timestamp = time.Now()
 			if !started {
 				emit(ch, Event{Type: EventMessageStart, Message: finalMsg})
@@ -435,6 +520,9 @@ func callLLMStream(ctx context.Context, model ChatModel, messages []Message, too
 	}
 
 	// Stream closed without done event — use partial
+	if err := checkEmptyResponse(partial); err != nil {
+		return Message{}, err
+	}
 	partial.Timestamp = time.Now()
 	if !started {
 		emit(ch, Event{Type: EventMessageStart, Message: partial})
@@ -443,6 +531,38 @@ func callLLMStream(ctx context.Context, model ChatModel, messages []Message, too
 	return partial, nil
 }
 
+// callLLMStreamGuarded drains the stream, guards the complete message, and
+// then emits it with one text delta per text block.
+func callLLMStreamGuarded(ctx context.Context, streamCh <-chan StreamEvent, guards []Guard, ch chan<- Event) (Message, error) {
+	var msg Message
+	for ev := range streamCh {
+		if ev.Type == StreamEventError {
+			return Message{}, ev.Err
+		}
+		msg = ev.Message
+		if ev.Type == StreamEventDone {
+			break
+		}
+	}
+	if err := checkEmptyResponse(msg); err != nil {
+		return Message{}, err
+	}
+	msg.Timestamp = time.Now()
+
+	msg, err := guardMessage(ctx, GuardOutput, guards, msg)
+	if err != nil {
+		return Message{}, err
+	}
+	emit(ch, Event{Type: EventMessageStart, Message: msg})
+	for _, b := range msg.Content {
+		if b.Type == ContentText && b.Text != "" {
+			emit(ch, Event{Type: EventMessageUpdate, Message: msg, Delta: b.Text, DeltaType: StreamEventTextDelta})
+		}
+	}
+	emit(ch, Event{Type: EventMessageEnd, Message: msg})
+	return msg, nil
+}
+
 // executeToolCalls runs tool calls sequentially, checking steering after each.
 // toolErrors tracks consecutive failures per tool for circuit breaking.
 func executeToolCalls(ctx context.Context, tools []Tool, calls []ToolCall, config LoopConfig, toolErrors map[string]int, ch chan<- Event) ([]ToolResult, []AgentMessage) {
@@ -452,6 +572,14 @@ func executeToolCalls(ctx context.Context, tools []Tool, calls []ToolCall, confi
 		tool := findTool(tools, call.Name)
 		label := toolLabel(tool)
 
+		// Repair malformed arguments before events, permission checks, and
+		// validation see them.
+		if config.RepairToolArgs && tool != nil && !json.Valid(call.Args) {
+			if repaired, ok := repairJSON(call.Args); ok {
+				call.Args = repaired
+			}
+		}
+
 		// Circuit breaker: skip if tool has exceeded consecutive failure threshold
 		if config.MaxToolErrors > 0 && toolErrors[call.Name] >= config.MaxToolErrors {
 			emit(ch, Event{
@@ -482,21 +610,18 @@ func executeToolCalls(ctx context.Context, tools []Tool, calls []ToolCall, confi
 			Args:      call.Args,
 		})
 
+		// Tool filter: reject tools that were not offered for this call, in
+		// case the model names one anyway.
+		if config.ToolFilter != nil && !config.ToolFilter(ctx, call.Name) {
+			results = append(results, denyToolCall(call, label, fmt.Errorf("tool %q is not available", call.Name), ch))
+			continue
+		}
+
 		// Permission check: deny before execution if callback returns error.
 		// Denial does NOT count toward toolErrors (policy decision, not tool failure).
 		if config.CheckPermission != nil {
 			if err := config.CheckPermission(ctx, call); err != nil {
-				errContent, _ := json.Marshal(err.Error())
-				result := ToolResult{ToolCallID: call.ID, Content: errContent, IsError: true}
-				emit(ch, Event{
-					Type:      EventToolExecEnd,
-					ToolID:    call.ID,
-					Tool:      call.Name,
-					ToolLabel: label,
-					Result:    result.Content,
-					IsError:   true,
-				})
-				results = append(results, result)
+				results = append(results, denyToolCall(call, label, err, ch))
 				continue
 			}
 		}
@@ -510,8 +635,11 @@ func executeToolCalls(ctx context.Context, tools []Tool, calls []ToolCall, confi
 				Content:    errContent,
 				IsError:    true,
 			}
-		} else if err := validateToolArgs(tool, call.Args); err != nil {
+		} else if args, err := validateToolArgs(tool, call.Args); err != nil {
 			// Argument validation failed — return error to LLM without counting as tool error.
+			if config.RepairToolArgs && !json.Valid(call.Args) {
+				err = argParseError(tool, call.Args)
+			}
 			errContent, _ := json.Marshal(err.Error())
 			result = ToolResult{
 				ToolCallID: call.ID,
@@ -519,6 +647,8 @@ func executeToolCalls(ctx context.Context, tools []Tool, calls []ToolCall, confi
 				IsError:    true,
 			}
 		} else {
+			call.Args = args // coerced to schema types
+
 			// Inject progress callback so tools can report partial results
 			progressCtx := WithToolProgress(ctx, func(partial json.RawMessage) {
 				emit(ch, Event{
@@ -539,12 +669,21 @@ func executeToolCalls(ctx context.Context, tools []Tool, calls []ToolCall, confi
 			} else {
 				output, execErr = tool.Execute(progressCtx, call.Args)
 			}
+			if errors.Is(execErr, ErrBudgetExceeded) {
+				vetoBudget(ctx, execErr)
+			}
 			err := execErr
+			if err == nil {
+				err = validateToolOutput(tool, output)
+			}
 			if err != nil {
-				errContent, _ := json.Marshal(err.Error())
+				partial := output
+				if execErr == nil {
+					partial = nil // output failed its schema; don't pass it on
+				}
 				result = ToolResult{
 					ToolCallID: call.ID,
-					Content:    errContent,
+					Content:    toolErrorContent(err, partial),
 					IsError:    true,
 				}
 			} else {
@@ -564,6 +703,11 @@ func executeToolCalls(ctx context.Context, tools []Tool, calls []ToolCall, confi
 			IsError:   result.IsError,
 		})
 
+		// Shrink the copy the model sees; the event above carries the full result.
+		if config.ProcessToolResult != nil && !result.IsError {
+			result.Content = config.ProcessToolResult(ctx, call, result.Content)
+		}
+
 		// Update consecutive error counter
 		if result.IsError {
 			toolErrors[call.Name]++
@@ -589,6 +733,31 @@ func executeToolCalls(ctx context.Context, tools []Tool, calls []ToolCall, confi
 	return results, nil
 }
 
+// denyToolCall emits tool_denied and tool_exec_end for a call rejected by the
+// tool filter or permission check, and returns err as its result.
+func denyToolCall(call ToolCall, label string, err error, ch chan<- Event) ToolResult {
+	emit(ch, Event{
+		Type:      EventToolDenied,
+		ToolID:    call.ID,
+		Tool:      call.Name,
+		ToolLabel: label,
+		Args:      call.Args,
+		Err:       err,
+	})
+
+	errContent, _ := json.Marshal(err.Error())
+	result := ToolResult{ToolCallID: call.ID, Content: errContent, IsError: true}
+	emit(ch, Event{
+		Type:      EventToolExecEnd,
+		ToolID:    call.ID,
+		Tool:      call.Name,
+		ToolLabel: label,
+		Result:    result.Content,
+		IsError:   true,
+	})
+	return result
+}
+
 // skipToolCall creates a skipped result for an interrupted tool call.
 func skipToolCall(call ToolCall, tools []Tool, ch chan<- Event) ToolResult {
 	label := toolLabel(findTool(tools, call.Name))
@@ -649,6 +818,20 @@ func toolLabel(tool Tool) string {
 	return ""
 }
 
+// filterTools returns the tools that filter allows for this call.
+func filterTools(ctx context.Context, tools []Tool, filter ToolFilter) []Tool {
+	if filter == nil {
+		return tools
+	}
+	allowed := make([]Tool, 0, len(tools))
+	for _, t := range tools {
+		if filter(ctx, t.Name()) {
+			allowed = append(allowed, t)
+		}
+	}
+	return allowed
+}
+
 // buildToolSpecs converts Tool interfaces to ToolSpec for the LLM.
 func buildToolSpecs(tools []Tool) []ToolSpec {
 	if len(tools) == 0 {
@@ -665,31 +848,176 @@ func buildToolSpecs(tools []Tool) []ToolSpec {
 	return specs
 }
 
-// validateToolArgs validates tool call arguments against the tool's JSON Schema.
-// Checks required fields and basic type matching. Returns nil if valid or schema is unavailable.
-// On failure, returns a formatted error message suitable for sending back to the LLM.
-func validateToolArgs(tool Tool, args json.RawMessage) error {
-	schema := tool.Schema()
+// ValidationError describes a tool argument or result that does not match
+// the tool's JSON Schema. Its message is sent back to the LLM as a tool error.
+type ValidationError struct {
+	Tool   string // tool name
+	Output bool   // true when validating the tool result rather than its arguments
+	Field  string // offending field, empty for whole-document errors
+	Reason string
+}
+
+func (e *ValidationError) Error() string {
+	what := "validation failed for tool"
+	if e.Output {
+		what = "output validation failed for tool"
+	}
+	if e.Tool != "" {
+		what += fmt.Sprintf(" %q", e.Tool)
+	} else {
+		what = strings.TrimSuffix(what, " for tool")
+	}
+	if e.Field == "" {
+		return fmt.Sprintf("%s: %s", what, e.Reason)
+	}
+	return fmt.Sprintf("%s: field %q: %s", what, e.Field, e.Reason)
+}
+
+// validateToolArgs coerces and validates tool call arguments against the tool's JSON Schema.
+// Returns the coerced arguments, or a *ValidationError suitable for sending back to the LLM.
+func validateToolArgs(tool Tool, args json.RawMessage) (json.RawMessage, error) {
+	args = coerceArgs(tool.Schema(), args)
+	return args, validateSchema(tool.Name(), tool.Schema(), args, false)
+}
+
+// ValidateArgs coerces args to the top-level property types declared in
+// schema, then checks required fields, types, and enum membership. The agent
+// loop already does this before every Execute; tools invoked by other paths
+// can call it directly. Returns the coerced arguments or a *ValidationError.
+//
+// Coercions: numeric strings to number/integer, "true"/"false" to boolean,
+// and numbers or booleans to string. Anything else is left for validation to reject.
+func ValidateArgs(schema map[string]any, args json.RawMessage) (json.RawMessage, error) {
+	args = coerceArgs(schema, args)
+	if err := validateSchema("", schema, args, false); err != nil {
+		return nil, err
+	}
+	return args, nil
+}
+
+// coerceArgs fixes common model mistakes such as quoting numbers. Only the
+// coerced fields are re-encoded; all others keep their original bytes, so
+// large integers never pass through float64. The input is returned unchanged
+// when nothing needs converting or it isn't an object.
+func coerceArgs(schema map[string]any, raw json.RawMessage) json.RawMessage {
+	props, ok := schema["properties"].(map[string]any)
+	if !ok {
+		return raw
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return raw
+	}
+
+	changed := false
+	for key, field := range fields {
+		ps, _ := props[key].(map[string]any)
+		expected, _ := ps["type"].(string)
+		if expected == "" {
+			continue
+		}
+		dec := json.NewDecoder(bytes.NewReader(field))
+		dec.UseNumber()
+		var val any
+		if err := dec.Decode(&val); err != nil {
+			continue
+		}
+		v, ok := coerceValue(val, expected)
+		if !ok {
+			continue
+		}
+		b, err := json.Marshal(v)
+		if err != nil {
+			continue
+		}
+		fields[key] = b
+		changed = true
+	}
+	if !changed {
+		return raw
+	}
+	out, err := json.Marshal(fields)
+	if err != nil {
+		return raw
+	}
+	return out
+}
+
+// coerceValue converts val (decoded with UseNumber) to the expected JSON type
+// when the conversion is lossless. Numbers keep their literal text.
+func coerceValue(val any, expected string) (any, bool) {
+	switch v := val.(type) {
+	case string:
+		s := strings.TrimSpace(v)
+		switch expected {
+		case "number":
+			if _, err := strconv.ParseFloat(s, 64); err == nil && json.Valid([]byte(s)) {
+				return json.Number(s), true
+			}
+		case "integer":
+			if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+				return n, true
+			}
+		case "boolean":
+			switch strings.ToLower(s) {
+			case "true":
+				return true, true
+			case "false":
+				return false, true
+			}
+		}
+	case json.Number:
+		if expected == "string" {
+			return v.String(), true
+		}
+	case bool:
+		if expected == "string" {
+			return strconv.FormatBool(v), true
+		}
+	}
+	return nil, false
+}
+
+// validateToolOutput validates a tool result against its declared output schema.
+// Tools that don't implement ToolOutputSchemaer are not checked.
+func validateToolOutput(tool Tool, output json.RawMessage) error {
+	schemaer, ok := tool.(ToolOutputSchemaer)
+	if !ok {
+		return nil
+	}
+	return validateSchema(tool.Name(), schemaer.OutputSchema(), output, true)
+}
+
+// validateSchema checks a JSON document against an object schema's required
+// fields and top-level property types.
+func validateSchema(toolName string, schema map[string]any, raw json.RawMessage, output bool) error {
 	if schema == nil {
 		return nil
 	}
 
-	// Parse arguments
+	// Parse document
 	var parsed map[string]any
-	if err := json.Unmarshal(args, &parsed); err != nil {
-		return fmt.Errorf("validation failed for tool %q: invalid JSON: %w", tool.Name(), err)
+	if err := json.Unmarshal(raw, &parsed); err != nil {
+		return &ValidationError{Tool: toolName, Output: output, Reason: "invalid JSON: " + err.Error()}
 	}
 
-	// Check required fields
-	if reqSlice, ok := schema["required"]; ok {
-		if required, ok := reqSlice.([]string); ok {
-			for _, field := range required {
-				if _, exists := parsed[field]; !exists {
-					return fmt.Errorf("validation failed for tool %q: missing required field %q", tool.Name(), field)
-				}
+	// Check required fields ([]string from the schema builder, []any from decoded JSON)
+	var required []string
+	switch req := schema["required"].(type) {
+	case []string:
+		required = req
+	case []any:
+		for _, r := range req {
+			if name, ok := r.(string); ok {
+				required = append(required, name)
 			}
 		}
 	}
+	for _, field := range required {
+		if _, exists := parsed[field]; !exists {
+			return &ValidationError{Tool: toolName, Output: output, Field: field, Reason: "missing required field"}
+		}
+	}
 
 	// Check property types
 	if props, ok := schema["properties"].(map[string]any); ok {
@@ -706,8 +1034,11 @@ func validateToolArgs(tool Tool, args json.RawMessage) error {
 			if expectedType == "" {
 				continue
 			}
-			if err := checkType(key, val, expectedType); err != nil {
-				return fmt.Errorf("validation failed for tool %q: %w", tool.Name(), err)
+			if reason := checkType(val, expectedType); reason != "" {
+				return &ValidationError{Tool: toolName, Output: output, Field: key, Reason: reason}
+			}
+			if reason := checkEnum(val, ps["enum"]); reason != "" {
+				return &ValidationError{Tool: toolName, Output: output, Field: key, Reason: reason}
 			}
 		}
 	}
@@ -716,39 +1047,104 @@ func validateToolArgs(tool Tool, args json.RawMessage) error {
 }
 
 // checkType validates a single value against an expected JSON Schema type.
-func checkType(field string, val any, expected string) error {
+// Returns an empty string when the value matches.
+func checkType(val any, expected string) string {
 	switch expected {
 	case "string":
 		if _, ok := val.(string); !ok {
-			return fmt.Errorf("field %q: expected string, got %T", field, val)
+			return fmt.Sprintf("expected string, got %T", val)
 		}
 	case "integer":
 		switch v := val.(type) {
 		case float64:
 			if v != float64(int64(v)) {
-				return fmt.Errorf("field %q: expected integer, got float", field)
+				return "expected integer, got float"
 			}
 		default:
-			return fmt.Errorf("field %q: expected integer, got %T", field, val)
+			return fmt.Sprintf("expected integer, got %T", val)
 		}
 	case "number":
 		if _, ok := val.(float64); !ok {
-			return fmt.Errorf("field %q: expected number, got %T", field, val)
+			return fmt.Sprintf("expected number, got %T", val)
 		}
 	case "boolean":
 		if _, ok := val.(bool); !ok {
-			return fmt.Errorf("field %q: expected boolean, got %T", field, val)
+			return fmt.Sprintf("expected boolean, got %T", val)
 		}
 	case "array":
 		if _, ok := val.([]any); !ok {
-			return fmt.Errorf("field %q: expected array, got %T", field, val)
+			return fmt.Sprintf("expected array, got %T", val)
 		}
 	case "object":
 		if _, ok := val.(map[string]any); !ok {
-			return fmt.Errorf("field %q: expected object, got %T", field, val)
+			return fmt.Sprintf("expected object, got %T", val)
 		}
 	}
-	return nil
+	return ""
+}
+
+// checkEnum reports a mismatch when enum is set and val is not one of its
+// values. Returns an empty string when enum is unset or val is allowed.
+// enum may be any slice ([]string from the schema builder, []any from decoded
+// JSON or hand-written schemas, []int, ...).
+func checkEnum(val any, enum any) string {
+	rv := reflect.ValueOf(enum)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return ""
+	}
+	allowed := make([]any, rv.Len())
+	for i := range allowed {
+		allowed[i] = rv.Index(i).Interface()
+	}
+	switch val.(type) {
+	case string, float64, bool, nil:
+		for _, a := range allowed {
+			if enumValue(a) == val {
+				return ""
+			}
+		}
+	}
+	return fmt.Sprintf("must be one of %v", allowed)
+}
+
+// enumValue converts an enum entry to the type json.Unmarshal produces for
+// the same argument, so Go ints match arguments decoded as float64.
+func enumValue(v any) any {
+	if n, ok := v.(json.Number); ok {
+		if f, err := n.Float64(); err == nil {
+			return f
+		}
+		return v
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(rv.Uint())
+	case reflect.Float32, reflect.Float64:
+		return rv.Float()
+	case reflect.String:
+		return rv.String()
+	case reflect.Bool:
+		return rv.Bool()
+	}
+	return v
+}
+
+// toolErrorContent encodes a failed tool call's result: the error message as a
+// JSON string or, when the tool also returned valid output (e.g. the steps a
+// canceled sub-agent chain completed), {"error": ..., "partial_output": ...}.
+func toolErrorContent(err error, partial json.RawMessage) json.RawMessage {
+	if len(partial) == 0 || string(partial) == "null" || !json.Valid(partial) {
+		content, _ := json.Marshal(err.Error())
+		return content
+	}
+	content, _ := json.Marshal(struct {
+		Error         string          `json:"error"`
+		PartialOutput json.RawMessage `json:"partial_output"`
+	}{err.Error(), partial})
+	return content
 }
 
 // buildMiddlewareChain wraps a tool's Execute with the middleware stack.
@@ -0,0 +1,92 @@
+package agentcore_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/voocel/agentcore"
+	"github.com/voocel/agentcore/schema"
+)
+
+func TestValidateArgsCoercion(t *testing.T) {
+	s := schema.Object(
+		schema.Property("id", schema.Int("")),
+		schema.Property("n", schema.Int("")),
+		schema.Property("ratio", schema.Number("")),
+		schema.Property("label", schema.String("")),
+		schema.Property("flag", schema.Bool("")),
+	)
+	tests := []struct {
+		name string
+		args string
+		want map[string]string // field -> expected raw JSON
+	}{
+		{"large integer untouched", `{"id":9007199254740993,"n":"5"}`, map[string]string{"id": "9007199254740993", "n": "5"}},
+		{"quoted large integer", `{"id":"9007199254740993"}`, map[string]string{"id": "9007199254740993"}},
+		{"quoted number keeps literal", `{"ratio":" 0.10000000000000001 "}`, map[string]string{"ratio": "0.10000000000000001"}},
+		{"number to string keeps literal", `{"label":12345678901234567890}`, map[string]string{"label": `"12345678901234567890"`}},
+		{"bool from string", `{"flag":"TRUE"}`, map[string]string{"flag": "true"}},
+		{"nothing to coerce", `{"n":3,"label":"x"}`, map[string]string{"n": "3", "label": `"x"`}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := agentcore.ValidateArgs(s, json.RawMessage(tt.args))
+			if err != nil {
+				t.Fatalf("ValidateArgs(%s): %v", tt.args, err)
+			}
+			var got map[string]json.RawMessage
+			if err := json.Unmarshal(out, &got); err != nil {
+				t.Fatal(err)
+			}
+			for field, want := range tt.want {
+				if string(got[field]) != want {
+					t.Errorf("%s = %s, want %s (output %s)", field, got[field], want, out)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateArgsRejects(t *testing.T) {
+	s := schema.Object(
+		schema.Property("n", schema.Int("")).Required(),
+		schema.Property("mode", schema.Enum("", "fast", "slow")),
+	)
+	for _, args := range []string{`{}`, `{"n":"five"}`, `{"n":1.5}`, `{"n":1,"mode":"medium"}`} {
+		if _, err := agentcore.ValidateArgs(s, json.RawMessage(args)); err == nil {
+			t.Errorf("ValidateArgs(%s) succeeded, want error", args)
+		}
+	}
+}
+
+func TestValidateArgsEnum(t *testing.T) {
+	level := func(enum any) map[string]any {
+		return map[string]any{
+			"type":       "object",
+			"properties": map[string]any{"level": map[string]any{"type": "number", "enum": enum}},
+		}
+	}
+	tests := []struct {
+		name string
+		enum any
+		args string
+		ok   bool
+	}{
+		{"[]any of ints", []any{1, 2, 3}, `{"level":2}`, true},
+		{"[]any of ints rejects", []any{1, 2, 3}, `{"level":4}`, false},
+		{"[]int", []int{1, 2, 3}, `{"level":3}`, true},
+		{"[]int rejects", []int{1, 2, 3}, `{"level":2.5}`, false},
+		{"[]float64", []float64{0.5, 1.5}, `{"level":1.5}`, true},
+		{"[]int64 rejects", []int64{10}, `{"level":11}`, false},
+		{"decoded JSON", []any{float64(1), float64(2)}, `{"level":1}`, true},
+		{"json.Number", []any{json.Number("7")}, `{"level":7}`, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := agentcore.ValidateArgs(level(tt.enum), json.RawMessage(tt.args))
+			if (err == nil) != tt.ok {
+				t.Fatalf("ValidateArgs(%s) with enum %v: err = %v, want ok = %v", tt.args, tt.enum, err, tt.ok)
+			}
+		})
+	}
+}
@@ -0,0 +1,361 @@
+package memory
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/voocel/agentcore"
+)
+
+const (
+	defaultRecallTopK         = 5
+	defaultRecallRecentWindow = 10
+	defaultRecallMinScore     = 0.3
+)
+
+// Embedder converts texts to embedding vectors.
+// Implementations must return one vector per input text, in order.
+type Embedder interface {
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+}
+
+// VectorStore stores message embeddings and answers nearest-neighbor queries.
+type VectorStore interface {
+	// Add stores a message under id. Adding an existing id is a no-op.
+	Add(ctx context.Context, id string, vec []float32, msg agentcore.AgentMessage) error
+	// Has reports whether id is already stored.
+	Has(id string) bool
+	// Search returns up to k stored entries ordered by descending similarity.
+	Search(ctx context.Context, vec []float32, k int) ([]ScoredMessage, error)
+}
+
+// ScoredMessage is a search hit with its similarity score.
+type ScoredMessage struct {
+	ID      string
+	Score   float64
+	Message agentcore.AgentMessage
+}
+
+// ---------------------------------------------------------------------------
+// In-memory cosine store
+// ---------------------------------------------------------------------------
+
+type vectorEntry struct {
+	id  string
+	vec []float32
+	msg agentcore.AgentMessage
+}
+
+// InMemoryVectorStore is a brute-force cosine-similarity store.
+// Suitable for single-process agents with up to a few thousand messages.
+type InMemoryVectorStore struct {
+	mu      sync.RWMutex
+	entries []vectorEntry
+	ids     map[string]struct{}
+}
+
+// NewInMemoryVectorStore creates an empty in-memory store.
+func NewInMemoryVectorStore() *InMemoryVectorStore {
+	return &InMemoryVectorStore{ids: make(map[string]struct{})}
+}
+
+func (s *InMemoryVectorStore) Add(_ context.Context, id string, vec []float32, msg agentcore.AgentMessage) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.ids[id]; ok {
+		return nil
+	}
+	s.ids[id] = struct{}{}
+	s.entries = append(s.entries, vectorEntry{id: id, vec: vec, msg: msg})
+	return nil
+}
+
+func (s *InMemoryVectorStore) Has(id string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	_, ok := s.ids[id]
+	return ok
+}
+
+func (s *InMemoryVectorStore) Search(_ context.Context, vec []float32, k int) ([]ScoredMessage, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	hits := make([]ScoredMessage, 0, len(s.entries))
+	for _, e := range s.entries {
+		hits = append(hits, ScoredMessage{ID: e.id, Score: CosineSimilarity(vec, e.vec), Message: e.msg})
+	}
+	slices.SortFunc(hits, func(a, b ScoredMessage) int {
+		switch {
+		case a.Score > b.Score:
+			return -1
+		case a.Score < b.Score:
+			return 1
+		}
+		return 0
+	})
+	if k > 0 && len(hits) > k {
+		hits = hits[:k]
+	}
+	return hits, nil
+}
+
+// CosineSimilarity returns the cosine of the angle between a and b.
+// Returns 0 for mismatched lengths or zero vectors.
+func CosineSimilarity(a, b []float32) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+	var dot, na, nb float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		na += float64(a[i]) * float64(a[i])
+		nb += float64(b[i]) * float64(b[i])
+	}
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(na) * math.Sqrt(nb))
+}
+
+// ---------------------------------------------------------------------------
+// Vector memory
+// ---------------------------------------------------------------------------
+
+// VectorConfig configures semantic recall.
+type VectorConfig struct {
+	// Embedder produces vectors for messages and queries. Required.
+	Embedder Embedder
+
+	// Store holds message vectors. Default: NewInMemoryVectorStore().
+	Store VectorStore
+
+	// TopK is the max number of recalled messages injected per call.
+	// Default: 5.
+	TopK int
+
+	// RecentWindow is the number of trailing non-system messages passed to
+	// the model; older ones are dropped and reach it only through recall.
+	// Default: 10.
+	RecentWindow int
+
+	// MinScore drops hits below this cosine similarity. Default: 0.3;
+	// < 0 disables the threshold.
+	MinScore float64
+}
+
+// Vector indexes conversation messages and recalls the most relevant earlier
+// ones for the current user query.
+type Vector struct {
+	cfg VectorConfig
+}
+
+// NewVector creates a vector memory.
+func NewVector(cfg VectorConfig) *Vector {
+	if cfg.Store == nil {
+		cfg.Store = NewInMemoryVectorStore()
+	}
+	if cfg.TopK <= 0 {
+		cfg.TopK = defaultRecallTopK
+	}
+	if cfg.RecentWindow <= 0 {
+		cfg.RecentWindow = defaultRecallRecentWindow
+	}
+	switch {
+	case cfg.MinScore == 0:
+		cfg.MinScore = defaultRecallMinScore
+	case cfg.MinScore < 0:
+		cfg.MinScore = math.Inf(-1)
+	}
+	return &Vector{cfg: cfg}
+}
+
+// Index embeds and stores any messages not yet in the store.
+// Only user and assistant text is indexed; tool traffic and custom types are skipped.
+func (v *Vector) Index(ctx context.Context, msgs []agentcore.AgentMessage) error {
+	var (
+		ids   []string
+		texts []string
+		items []agentcore.AgentMessage
+	)
+	for _, m := range msgs {
+		text := indexableText(m)
+		if text == "" {
+			continue
+		}
+		id := messageID(m)
+		if v.cfg.Store.Has(id) || slices.Contains(ids, id) {
+			continue
+		}
+		ids = append(ids, id)
+		texts = append(texts, text)
+		items = append(items, m)
+	}
+	if len(texts) == 0 {
+		return nil
+	}
+
+	vecs, err := v.cfg.Embedder.Embed(ctx, texts)
+	if err != nil {
+		return fmt.Errorf("embed messages: %w", err)
+	}
+	if len(vecs) != len(texts) {
+		return fmt.Errorf("embed messages: got %d vectors for %d texts", len(vecs), len(texts))
+	}
+	for i, id := range ids {
+		if err := v.cfg.Store.Add(ctx, id, vecs[i], items[i]); err != nil {
+			return fmt.Errorf("store message: %w", err)
+		}
+	}
+	return nil
+}
+
+// Retrieve returns the k stored messages most similar to query.
+func (v *Vector) Retrieve(ctx context.Context, query string, k int) ([]ScoredMessage, error) {
+	vecs, err := v.cfg.Embedder.Embed(ctx, []string{query})
+	if err != nil {
+		return nil, fmt.Errorf("embed query: %w", err)
+	}
+	if len(vecs) == 0 {
+		return nil, nil
+	}
+	return v.cfg.Store.Search(ctx, vecs[0], k)
+}
+
+// Transform returns a TransformContext function that indexes the full
+// history, trims it to the last RecentWindow messages, and injects the most
+// relevant dropped messages before the latest user message. Recalled messages therefore never duplicate ones the model
+// already sees.
+//
+// Usage:
+//
+//	recall := memory.NewVector(memory.VectorConfig{Embedder: llm.NewOpenAIEmbedder("text-embedding-3-small", key)})
+//	agent := agentcore.NewAgent(agentcore.WithTransformContext(recall.Transform()))
+func (v *Vector) Transform() func(context.Context, []agentcore.AgentMessage) ([]agentcore.AgentMessage, error) {
+	return func(ctx context.Context, msgs []agentcore.AgentMessage) ([]agentcore.AgentMessage, error) {
+		kept := recentMessages(msgs, v.cfg.RecentWindow)
+		if len(kept) == len(msgs) {
+			return msgs, nil // nothing dropped, nothing to recall
+		}
+		queryIdx := lastUserIndex(kept)
+		if queryIdx < 0 {
+			return kept, nil
+		}
+		query := kept[queryIdx].TextContent()
+		if strings.TrimSpace(query) == "" {
+			return kept, nil
+		}
+
+		if err := v.Index(ctx, msgs); err != nil {
+			return nil, fmt.Errorf("vector memory: %w", err)
+		}
+
+		// Over-fetch so hits inside the kept window can be dropped.
+		hits, err := v.Retrieve(ctx, query, v.cfg.TopK+len(kept))
+		if err != nil {
+			return nil, fmt.Errorf("vector memory: %w", err)
+		}
+
+		visible := make(map[string]struct{}, len(kept))
+		for _, m := range kept {
+			visible[messageID(m)] = struct{}{}
+		}
+
+		var recalled []string
+		for _, h := range hits {
+			if len(recalled) >= v.cfg.TopK || h.Score < v.cfg.MinScore {
+				break
+			}
+			if _, ok := visible[h.ID]; ok {
+				continue
+			}
+			recalled = append(recalled, fmt.Sprintf("[%s]: %s", h.Message.GetRole(), h.Message.TextContent()))
+		}
+		if len(recalled) == 0 {
+			return kept, nil
+		}
+
+		note := agentcore.Message{
+			Role:      agentcore.RoleUser,
+			Content:   []agentcore.ContentBlock{agentcore.TextBlock("<recalled-context>\n" + strings.Join(recalled, "\n\n") + "\n</recalled-context>")},
+			Metadata:  map[string]any{"type": "recalled_context"},
+			Timestamp: time.Now(),
+		}
+
+		out := make([]agentcore.AgentMessage, 0, len(kept)+1)
+		out = append(out, kept[:queryIdx]...)
+		out = append(out, note)
+		out = append(out, kept[queryIdx:]...)
+		return out, nil
+	}
+}
+
+// recentMessages keeps system messages and the last n others. The window is
+// widened to include the latest user message, and it never starts with tool
+// results whose call was dropped. n <= 0 keeps everything.
+func recentMessages(msgs []agentcore.AgentMessage, n int) []agentcore.AgentMessage {
+	if n <= 0 {
+		return msgs
+	}
+	var system, rest []agentcore.AgentMessage
+	for _, m := range msgs {
+		if m.GetRole() == agentcore.RoleSystem {
+			system = append(system, m)
+		} else {
+			rest = append(rest, m)
+		}
+	}
+	if len(rest) <= n {
+		return msgs
+	}
+
+	start := len(rest) - n
+	if lastUser := lastUserIndex(rest); lastUser >= 0 && lastUser < start {
+		start = lastUser
+	}
+	for start < len(rest) && rest[start].GetRole() == agentcore.RoleTool {
+		start++
+	}
+
+	out := make([]agentcore.AgentMessage, 0, len(system)+len(rest)-start)
+	out = append(out, system...)
+	return append(out, rest[start:]...)
+}
+
+// indexableText returns the text worth embedding for a message, or "".
+func indexableText(m agentcore.AgentMessage) string {
+	msg, ok := m.(agentcore.Message)
+	if !ok || (msg.Role != agentcore.RoleUser && msg.Role != agentcore.RoleAssistant) {
+		return ""
+	}
+	if t, _ := msg.Metadata["type"].(string); t == "recalled_context" {
+		return ""
+	}
+	return strings.TrimSpace(msg.TextContent())
+}
+
+// messageID derives a stable identity from role, timestamp and text.
+func messageID(m agentcore.AgentMessage) string {
+	h := sha256.New()
+	h.Write([]byte(m.GetRole()))
+	h.Write([]byte(m.GetTimestamp().UTC().Format(time.RFC3339Nano)))
+	h.Write([]byte(m.TextContent()))
+	return hex.EncodeToString(h.Sum(nil)[:16])
+}
+
+// lastUserIndex returns the index of the last user Message, or -1.
+func lastUserIndex(msgs []agentcore.AgentMessage) int {
+	for i := len(msgs) - 1; i >= 0; i-- {
+		if msg, ok := msgs[i].(agentcore.Message); ok && msg.Role == agentcore.RoleUser {
+			return i
+		}
+	}
+	return -1
+}
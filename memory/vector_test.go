@@ -0,0 +1,105 @@
+package memory
+
+import (
+	"context"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/voocel/agentcore"
+)
+
+// keywordEmbedder maps each text to a one-hot vector over a fixed vocabulary.
+type keywordEmbedder []string
+
+func (e keywordEmbedder) Embed(_ context.Context, texts []string) ([][]float32, error) {
+	vecs := make([][]float32, len(texts))
+	for i, t := range texts {
+		vecs[i] = make([]float32, len(e))
+		for j, w := range e {
+			if strings.Contains(t, w) {
+				vecs[i][j] = 1
+			}
+		}
+	}
+	return vecs, nil
+}
+
+func TestVectorTransformTrimsAndRecalls(t *testing.T) {
+	base := time.Unix(1700000000, 0)
+	msg := func(i int, role agentcore.Role, text string) agentcore.AgentMessage {
+		return agentcore.Message{Role: role, Content: []agentcore.ContentBlock{agentcore.TextBlock(text)}, Timestamp: base.Add(time.Duration(i) * time.Second)}
+	}
+	msgs := []agentcore.AgentMessage{
+		msg(0, agentcore.RoleUser, "my cat is called tom"),
+		msg(1, agentcore.RoleAssistant, "nice"),
+		msg(2, agentcore.RoleUser, "weather today"),
+		msg(3, agentcore.RoleAssistant, "sunny"),
+		msg(4, agentcore.RoleUser, "what is my cat called?"),
+	}
+	v := NewVector(VectorConfig{Embedder: keywordEmbedder{"cat", "weather", "sunny", "nice"}, RecentWindow: 2, MinScore: 0.5})
+
+	out, err := v.Transform()(context.Background(), msgs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// sunny + recalled note + query
+	if len(out) != 3 {
+		t.Fatalf("got %d messages, want 3", len(out))
+	}
+	if out[0].TextContent() != "sunny" || out[2].TextContent() != "what is my cat called?" {
+		t.Fatalf("window not kept: %q ... %q", out[0].TextContent(), out[2].TextContent())
+	}
+	note := out[1].TextContent()
+	if !strings.Contains(note, "my cat is called tom") {
+		t.Fatalf("note missing recalled message: %q", note)
+	}
+	if strings.Contains(note, "sunny") {
+		t.Fatalf("note duplicates a visible message: %q", note)
+	}
+}
+
+func TestVectorTransformShortHistoryUnchanged(t *testing.T) {
+	msgs := []agentcore.AgentMessage{agentcore.UserMsg("hi")}
+	v := NewVector(VectorConfig{Embedder: keywordEmbedder{"hi"}})
+	out, err := v.Transform()(context.Background(), msgs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(out) != 1 {
+		t.Fatalf("got %d messages, want 1", len(out))
+	}
+}
+
+func TestNewVectorMinScore(t *testing.T) {
+	tests := []struct {
+		min  float64
+		want float64
+	}{
+		{0, defaultRecallMinScore},
+		{0.8, 0.8},
+		{-1, math.Inf(-1)},
+	}
+	for _, tt := range tests {
+		if got := NewVector(VectorConfig{MinScore: tt.min}).cfg.MinScore; got != tt.want {
+			t.Errorf("MinScore %v resolved to %v, want %v", tt.min, got, tt.want)
+		}
+	}
+}
+
+func TestVectorTransformNoThreshold(t *testing.T) {
+	msgs := []agentcore.AgentMessage{
+		agentcore.Message{Role: agentcore.RoleUser, Content: []agentcore.ContentBlock{agentcore.TextBlock("dogs bark")}, Timestamp: time.Unix(1, 0)},
+		agentcore.Message{Role: agentcore.RoleUser, Content: []agentcore.ContentBlock{agentcore.TextBlock("cats purr")}, Timestamp: time.Unix(2, 0)},
+	}
+	// Orthogonal vectors score 0, so only a disabled threshold recalls them.
+	v := NewVector(VectorConfig{Embedder: keywordEmbedder{"dogs", "cats"}, RecentWindow: 1, MinScore: -1})
+	out, err := v.Transform()(context.Background(), msgs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(out) != 2 || !strings.Contains(out[0].TextContent(), "dogs bark") {
+		t.Fatalf("got %d messages (%v), want the unrelated message recalled", len(out), out)
+	}
+}
@@ -0,0 +1,187 @@
+package memory
+
+import (
+	"context"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/voocel/agentcore"
+)
+
+const truncationMarker = "\n\n[... truncated to fit context budget ...]"
+
+// TokenCounter counts tokens for a single message.
+type TokenCounter interface {
+	Count(msg agentcore.AgentMessage) int
+}
+
+// TokenCounterFunc adapts a function to the TokenCounter interface.
+type TokenCounterFunc func(msg agentcore.AgentMessage) int
+
+func (f TokenCounterFunc) Count(msg agentcore.AgentMessage) int { return f(msg) }
+
+// CharCounter approximates BPE tokenizers (tiktoken-style) at ~4 chars per token.
+// This is the default counter.
+var CharCounter TokenCounter = TokenCounterFunc(EstimateTokens)
+
+// WordCounter is a naive fallback: ~0.75 words per token.
+var WordCounter TokenCounter = TokenCounterFunc(func(msg agentcore.AgentMessage) int {
+	words := len(strings.Fields(msg.TextContent() + " " + msg.ThinkingContent()))
+	if m, ok := msg.(agentcore.Message); ok {
+		for _, tc := range m.ToolCalls() {
+			words += len(strings.Fields(tc.Name + " " + string(tc.Args)))
+		}
+	}
+	return max((words*4+2)/3, 1)
+})
+
+// NewTokenWindow returns a TransformContext function that keeps the most
+// recent messages fitting within maxTokens.
+//
+// Rules:
+//   - System messages in the history are always kept
+//   - The most recent user message is always kept; if it alone exceeds the
+//     budget, its text is truncated with a marker instead of being dropped
+//   - The window never starts with orphaned tool results
+//
+// The agent's system prompt is not part of the history and is unaffected.
+// When counter is nil, CharCounter is used.
+func NewTokenWindow(maxTokens int, counter TokenCounter) func(context.Context, []agentcore.AgentMessage) ([]agentcore.AgentMessage, error) {
+	if counter == nil {
+		counter = CharCounter
+	}
+
+	return func(_ context.Context, msgs []agentcore.AgentMessage) ([]agentcore.AgentMessage, error) {
+		if maxTokens <= 0 || len(msgs) == 0 {
+			return msgs, nil
+		}
+
+		total := 0
+		for _, m := range msgs {
+			total += counter.Count(m)
+		}
+		if total <= maxTokens {
+			return msgs, nil
+		}
+
+		// Reserve budget for pinned system messages.
+		budget := maxTokens
+		var system []agentcore.AgentMessage
+		for _, m := range msgs {
+			if m.GetRole() == agentcore.RoleSystem {
+				system = append(system, m)
+				budget -= counter.Count(m)
+			}
+		}
+
+		lastUser := lastUserIndex(msgs)
+
+		// Walk backwards, keeping messages while they fit. Everything from the
+		// last user message onward is mandatory.
+		start := len(msgs)
+		used := 0
+		for i := len(msgs) - 1; i >= 0; i-- {
+			if msgs[i].GetRole() == agentcore.RoleSystem {
+				continue
+			}
+			n := counter.Count(msgs[i])
+			if used+n > budget && (lastUser < 0 || i < lastUser) {
+				break
+			}
+			used += n
+			start = i
+		}
+
+		// Don't open the window on tool results whose call was dropped.
+		for start < len(msgs) && msgs[start].GetRole() == agentcore.RoleTool {
+			start++
+		}
+
+		out := make([]agentcore.AgentMessage, 0, len(system)+len(msgs)-start)
+		out = append(out, system...)
+		for _, m := range msgs[start:] {
+			if m.GetRole() != agentcore.RoleSystem {
+				out = append(out, m)
+			}
+		}
+
+		// Mandatory tail still over budget: truncate the largest messages.
+		if used > budget {
+			out = shrinkToBudget(out, len(system), budget, counter)
+		}
+		return out, nil
+	}
+}
+
+// NewMessageWindow returns a TransformContext function that keeps only the
+// most recent n non-system messages. System messages are always kept, the
+// window is widened to include the latest user message, and it never starts
+// with tool results whose call was dropped.
+func NewMessageWindow(n int) func(context.Context, []agentcore.AgentMessage) ([]agentcore.AgentMessage, error) {
+	return func(_ context.Context, msgs []agentcore.AgentMessage) ([]agentcore.AgentMessage, error) {
+		return recentMessages(msgs, n), nil
+	}
+}
+
+// shrinkToBudget truncates text in non-pinned messages (largest first) until
+// the total fits budget or nothing more can be cut.
+func shrinkToBudget(msgs []agentcore.AgentMessage, pinned, budget int, counter TokenCounter) []agentcore.AgentMessage {
+	for range len(msgs) {
+		used, largest, largestN := 0, -1, 0
+		for i := pinned; i < len(msgs); i++ {
+			n := counter.Count(msgs[i])
+			used += n
+			if n > largestN {
+				largest, largestN = i, n
+			}
+		}
+		if used <= budget || largest < 0 {
+			break
+		}
+
+		msg, ok := msgs[largest].(agentcore.Message)
+		if !ok {
+			break
+		}
+		target := max(largestN-(used-budget), 1)
+		truncated, changed := truncateMessageText(msg, target, counter)
+		if !changed {
+			break
+		}
+		msgs[largest] = truncated
+	}
+	return msgs
+}
+
+// truncateMessageText cuts the message's text blocks proportionally so the
+// message counts roughly target tokens, appending a truncation marker.
+func truncateMessageText(msg agentcore.Message, target int, counter TokenCounter) (agentcore.Message, bool) {
+	current := counter.Count(msg)
+	if current <= target {
+		return msg, false
+	}
+	ratio := float64(target) / float64(current)
+
+	content := make([]agentcore.ContentBlock, len(msg.Content))
+	copy(content, msg.Content)
+	changed := false
+	for i, b := range content {
+		if b.Type != agentcore.ContentText || len(b.Text) <= len(truncationMarker) {
+			continue
+		}
+		keep := int(float64(len(b.Text))*ratio) - len(truncationMarker)
+		if keep < 0 {
+			keep = 0
+		}
+		if keep >= len(b.Text) {
+			continue
+		}
+		for keep > 0 && !utf8.RuneStart(b.Text[keep]) {
+			keep--
+		}
+		content[i].Text = b.Text[:keep] + truncationMarker
+		changed = true
+	}
+	msg.Content = content
+	return msg, changed
+}
@@ -0,0 +1,54 @@
+package memory
+
+import (
+	"context"
+	"testing"
+
+	"github.com/voocel/agentcore"
+)
+
+func TestNewMessageWindow(t *testing.T) {
+	sys := agentcore.SystemMsg("rules")
+	u := func(s string) agentcore.AgentMessage { return agentcore.UserMsg(s) }
+	a := func(s string) agentcore.AgentMessage {
+		return agentcore.Message{Role: agentcore.RoleAssistant, Content: []agentcore.ContentBlock{agentcore.TextBlock(s)}}
+	}
+	call := agentcore.Message{Role: agentcore.RoleAssistant, Content: []agentcore.ContentBlock{
+		agentcore.ToolCallBlock(agentcore.ToolCall{ID: "1", Name: "ls"}),
+	}}
+	result := agentcore.ToolResultMsg("1", []byte(`"ok"`), false)
+
+	tests := []struct {
+		name string
+		n    int
+		msgs []agentcore.AgentMessage
+		want []string // TextContent of kept messages, in order
+	}{
+		{"under limit", 5, []agentcore.AgentMessage{u("q1"), a("a1")}, []string{"q1", "a1"}},
+		{"keeps last n", 2, []agentcore.AgentMessage{u("q1"), a("a1"), u("q2"), a("a2")}, []string{"q2", "a2"}},
+		{"system always kept", 2, []agentcore.AgentMessage{sys, u("q1"), a("a1"), u("q2"), a("a2")}, []string{"rules", "q2", "a2"}},
+		{"widened to last user", 1, []agentcore.AgentMessage{u("q1"), u("q2"), a("a2")}, []string{"q2", "a2"}},
+		{"no orphaned tool result", 2, []agentcore.AgentMessage{a("a0"), call, result, a("done")}, []string{"done"}},
+		{"disabled", 0, []agentcore.AgentMessage{u("q1"), a("a1")}, []string{"q1", "a1"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := NewMessageWindow(tt.n)(context.Background(), tt.msgs)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, m := range out {
+				got = append(got, m.TextContent())
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %q, want %q", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("got %q, want %q", got, tt.want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,165 @@
+// Package middleware provides reusable agentcore.ToolMiddleware implementations.
+package middleware
+
+import (
+	"container/list"
+	"context"
+	"encoding/json"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/voocel/agentcore"
+)
+
+const defaultCacheCapacity = 256
+
+// ToolCache stores tool results by key. Implementations must be safe for
+// concurrent use. A Redis-backed cache only needs these two methods.
+type ToolCache interface {
+	Get(key string) (json.RawMessage, bool)
+	Set(key string, value json.RawMessage, ttl time.Duration)
+}
+
+// CacheConfig configures the tool result cache middleware.
+type CacheConfig struct {
+	// Cache stores results. Default: NewLRUCache(256).
+	Cache ToolCache
+
+	// TTL is how long a result stays valid. 0 means no expiry.
+	TTL time.Duration
+
+	// Cacheable reports whether results of the named tool may be cached.
+	// nil caches every tool; use CacheOnly to restrict to read-only tools.
+	Cacheable func(name string) bool
+
+	// OnLookup is called on every cacheable call with the hit/miss outcome.
+	OnLookup func(call agentcore.ToolCall, hit bool)
+}
+
+// CacheOnly returns a Cacheable predicate allowing only the named tools.
+func CacheOnly(names ...string) func(string) bool {
+	return func(name string) bool { return slices.Contains(names, name) }
+}
+
+// Cache returns a middleware that memoizes successful tool results keyed by
+// tool name and canonicalized arguments. Errors are never cached.
+//
+// Usage:
+//
+//	agentcore.WithMiddlewares(middleware.Cache(middleware.CacheConfig{
+//	    TTL:       10 * time.Minute,
+//	    Cacheable: middleware.CacheOnly("read", "grep", "web_search"),
+//	}))
+func Cache(cfg CacheConfig) agentcore.ToolMiddleware {
+	if cfg.Cache == nil {
+		cfg.Cache = NewLRUCache(defaultCacheCapacity)
+	}
+
+	return func(ctx context.Context, call agentcore.ToolCall, next agentcore.ToolExecuteFunc) (json.RawMessage, error) {
+		if cfg.Cacheable != nil && !cfg.Cacheable(call.Name) {
+			return next(ctx, call.Args)
+		}
+
+		key := cacheKey(call)
+		if v, ok := cfg.Cache.Get(key); ok {
+			if cfg.OnLookup != nil {
+				cfg.OnLookup(call, true)
+			}
+			return v, nil
+		}
+		if cfg.OnLookup != nil {
+			cfg.OnLookup(call, false)
+		}
+
+		out, err := next(ctx, call.Args)
+		if err == nil {
+			cfg.Cache.Set(key, out, cfg.TTL)
+		}
+		return out, err
+	}
+}
+
+// cacheKey builds name + canonical JSON args. Re-marshaling decoded JSON
+// sorts object keys, so {"b":1,"a":2} and {"a":2,"b":1} share a key.
+func cacheKey(call agentcore.ToolCall) string {
+	var v any
+	if err := json.Unmarshal(call.Args, &v); err != nil {
+		return call.Name + "\x00" + string(call.Args)
+	}
+	canon, err := json.Marshal(v)
+	if err != nil {
+		return call.Name + "\x00" + string(call.Args)
+	}
+	return call.Name + "\x00" + string(canon)
+}
+
+// ---------------------------------------------------------------------------
+// In-memory LRU cache
+// ---------------------------------------------------------------------------
+
+type lruEntry struct {
+	key     string
+	value   json.RawMessage
+	expires time.Time // zero = never
+}
+
+// LRUCache is an in-memory ToolCache with least-recently-used eviction.
+type LRUCache struct {
+	mu       sync.Mutex
+	capacity int
+	ll       *list.List
+	items    map[string]*list.Element
+}
+
+// NewLRUCache creates an LRU cache holding up to capacity entries.
+func NewLRUCache(capacity int) *LRUCache {
+	if capacity <= 0 {
+		capacity = defaultCacheCapacity
+	}
+	return &LRUCache{capacity: capacity, ll: list.New(), items: make(map[string]*list.Element)}
+}
+
+func (c *LRUCache) Get(key string) (json.RawMessage, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+	e := el.Value.(*lruEntry)
+	if !e.expires.IsZero() && time.Now().After(e.expires) {
+		c.ll.Remove(el)
+		delete(c.items, key)
+		return nil, false
+	}
+	c.ll.MoveToFront(el)
+	return e.value, true
+}
+
+func (c *LRUCache) Set(key string, value json.RawMessage, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var expires time.Time
+	if ttl > 0 {
+		expires = time.Now().Add(ttl)
+	}
+	if el, ok := c.items[key]; ok {
+		el.Value = &lruEntry{key: key, value: value, expires: expires}
+		c.ll.MoveToFront(el)
+		return
+	}
+	c.items[key] = c.ll.PushFront(&lruEntry{key: key, value: value, expires: expires})
+	for c.ll.Len() > c.capacity {
+		oldest := c.ll.Back()
+		c.ll.Remove(oldest)
+		delete(c.items, oldest.Value.(*lruEntry).key)
+	}
+}
+
+// Len returns the number of cached entries (including expired ones not yet evicted).
+func (c *LRUCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.ll.Len()
+}
@@ -0,0 +1,172 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/voocel/agentcore"
+)
+
+// Limiter is a token bucket: it refills at rate tokens per second up to
+// burst, and each call consumes one token. Share one Limiter between agents
+// that share an API key.
+type Limiter struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// NewLimiter allows ratePerSecond calls on average with bursts up to burst.
+// For per-minute quotas use e.g. NewLimiter(60.0/60, 10).
+func NewLimiter(ratePerSecond float64, burst int) *Limiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &Limiter{rate: ratePerSecond, burst: float64(burst), tokens: float64(burst), last: time.Now()}
+}
+
+// Wait blocks until a token is available or ctx is done, and returns how long
+// it waited. A canceled wait gives its token back, and a context that is
+// already done takes none.
+func (l *Limiter) Wait(ctx context.Context) (time.Duration, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	delay := l.reserve()
+	if delay <= 0 {
+		return 0, nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return delay, nil
+	case <-ctx.Done():
+		l.refund()
+		return 0, ctx.Err()
+	}
+}
+
+// refund returns a token taken by Wait.
+func (l *Limiter) refund() {
+	l.mu.Lock()
+	l.tokens = min(l.tokens+1, l.burst)
+	l.mu.Unlock()
+}
+
+// reserve takes a token, possibly going into debt, and returns the delay
+// until the debt is repaid.
+func (l *Limiter) reserve() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := time.Now()
+	if l.rate > 0 {
+		l.tokens = min(l.tokens+now.Sub(l.last).Seconds()*l.rate, l.burst)
+	}
+	l.last = now
+	l.tokens--
+	if l.tokens >= 0 {
+		return 0
+	}
+	if l.rate <= 0 {
+		return time.Duration(1<<63 - 1) // no refill: wait until ctx is done
+	}
+	return time.Duration(-l.tokens / l.rate * float64(time.Second))
+}
+
+// RateLimitConfig configures the tool rate limit middleware.
+type RateLimitConfig struct {
+	// Limiters maps a key (by default the tool name) to its limiter.
+	Limiters map[string]*Limiter
+
+	// Default applies to keys without an entry in Limiters. Nil = unlimited.
+	Default *Limiter
+
+	// Key derives the limiter key from a call. Default: the tool name.
+	Key func(call agentcore.ToolCall) string
+
+	// OnWait is called after every wait with the time spent blocked.
+	OnWait func(key string, waited time.Duration)
+}
+
+// RateLimit returns a middleware that blocks tool calls until their limiter
+// has capacity, instead of letting them fail against an upstream quota.
+// Cancellation of the run context aborts the wait.
+//
+// Usage:
+//
+//	agentcore.WithMiddlewares(middleware.RateLimit(middleware.RateLimitConfig{
+//	    Limiters: map[string]*middleware.Limiter{"web_search": middleware.NewLimiter(1, 3)},
+//	}))
+func RateLimit(cfg RateLimitConfig) agentcore.ToolMiddleware {
+	return func(ctx context.Context, call agentcore.ToolCall, next agentcore.ToolExecuteFunc) (json.RawMessage, error) {
+		key := call.Name
+		if cfg.Key != nil {
+			key = cfg.Key(call)
+		}
+		limiter := cfg.Limiters[key]
+		if limiter == nil {
+			limiter = cfg.Default
+		}
+		if limiter != nil {
+			waited, err := limiter.Wait(ctx)
+			if err != nil {
+				return nil, err
+			}
+			if cfg.OnWait != nil {
+				cfg.OnWait(key, waited)
+			}
+		}
+		return next(ctx, call.Args)
+	}
+}
+
+// RateLimitModel wraps a ChatModel so every LLM call waits for limiter.
+// Give each model its own limiter for per-model quotas, or share one across
+// models that draw from the same key. Retries made by the agent loop pass
+// through the wrapper and wait as well, but a stream that fails to open gives
+// its token back, so the loop's Generate fallback costs one token in total.
+// onWait may be nil.
+//
+// Usage:
+//
+//	gpt4 := middleware.RateLimitModel(model, middleware.NewLimiter(0.5, 2), nil)
+func RateLimitModel(model agentcore.ChatModel, limiter *Limiter, onWait func(waited time.Duration)) agentcore.ChatModel {
+	return &rateLimitedModel{ChatModel: model, limiter: limiter, onWait: onWait}
+}
+
+type rateLimitedModel struct {
+	agentcore.ChatModel
+	limiter *Limiter
+	onWait  func(time.Duration)
+}
+
+func (m *rateLimitedModel) wait(ctx context.Context) error {
+	waited, err := m.limiter.Wait(ctx)
+	if err == nil && m.onWait != nil {
+		m.onWait(waited)
+	}
+	return err
+}
+
+func (m *rateLimitedModel) Generate(ctx context.Context, messages []agentcore.Message, tools []agentcore.ToolSpec, opts ...agentcore.CallOption) (*agentcore.LLMResponse, error) {
+	if err := m.wait(ctx); err != nil {
+		return nil, err
+	}
+	return m.ChatModel.Generate(ctx, messages, tools, opts...)
+}
+
+func (m *rateLimitedModel) GenerateStream(ctx context.Context, messages []agentcore.Message, tools []agentcore.ToolSpec, opts ...agentcore.CallOption) (<-chan agentcore.StreamEvent, error) {
+	if err := m.wait(ctx); err != nil {
+		return nil, err
+	}
+	ch, err := m.ChatModel.GenerateStream(ctx, messages, tools, opts...)
+	if err != nil {
+		m.limiter.refund() // the Generate fallback takes it again
+	}
+	return ch, err
+}
@@ -0,0 +1,89 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/voocel/agentcore"
+)
+
+func TestLimiterBurst(t *testing.T) {
+	l := NewLimiter(100, 3)
+	for i := range 3 {
+		if waited, err := l.Wait(context.Background()); err != nil || waited != 0 {
+			t.Fatalf("call %d waited %v (err %v), want an immediate token", i+1, waited, err)
+		}
+	}
+	if waited, err := l.Wait(context.Background()); err != nil || waited <= 0 {
+		t.Fatalf("call 4 waited %v (err %v), want a wait once the burst is spent", waited, err)
+	}
+}
+
+func TestLimiterWait(t *testing.T) {
+	l := NewLimiter(20, 1) // one token every 50ms
+	l.Wait(context.Background())
+
+	start := time.Now()
+	waited, err := l.Wait(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); waited < 30*time.Millisecond || elapsed < 30*time.Millisecond || elapsed > time.Second {
+		t.Fatalf("waited %v (reported %v), want about 50ms", elapsed, waited)
+	}
+}
+
+func TestLimiterWaitCanceled(t *testing.T) {
+	l := NewLimiter(0, 1) // no refill
+	l.Wait(context.Background())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := l.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, want DeadlineExceeded", err)
+	}
+	if _, err := l.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, want the done context to fail fast", err)
+	}
+	if l.tokens != 0 {
+		t.Fatalf("tokens = %v, want canceled waits to give their tokens back", l.tokens)
+	}
+}
+
+// noStreamModel fails to open streams, like an endpoint without streaming.
+type noStreamModel struct{ generated int }
+
+func (m *noStreamModel) SupportsTools() bool { return false }
+
+func (m *noStreamModel) Generate(context.Context, []agentcore.Message, []agentcore.ToolSpec, ...agentcore.CallOption) (*agentcore.LLMResponse, error) {
+	m.generated++
+	return &agentcore.LLMResponse{Message: agentcore.Message{Role: agentcore.RoleAssistant}}, nil
+}
+
+func (m *noStreamModel) GenerateStream(context.Context, []agentcore.Message, []agentcore.ToolSpec, ...agentcore.CallOption) (<-chan agentcore.StreamEvent, error) {
+	return nil, errors.New("streaming not supported")
+}
+
+func TestRateLimitModelStreamFallbackTakesOneToken(t *testing.T) {
+	inner := &noStreamModel{}
+	model := RateLimitModel(inner, NewLimiter(0, 1), nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if _, err := model.GenerateStream(ctx, nil, nil); err == nil {
+		t.Fatal("stream opened, want an error")
+	}
+	if _, err := model.Generate(ctx, nil, nil); err != nil {
+		t.Fatalf("fallback Generate: %v, want the call's single token", err)
+	}
+	short, cancelShort := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancelShort()
+	if _, err := model.Generate(short, nil, nil); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("second call err = %v, want it to wait for a token", err)
+	}
+	if inner.generated != 1 {
+		t.Fatalf("inner Generate called %d times, want 1", inner.generated)
+	}
+}
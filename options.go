@@ -15,6 +15,24 @@ func WithSystemPrompt(prompt string) AgentOption {
 	return func(a *Agent) { a.systemPrompt = prompt }
 }
 
+// WithSystemPromptTemplate sets a text/template system prompt rendered before
+// every LLM call. Templates see .Now (time.Time), .Tools (tool names), a
+// join function, and the variables returned by vars (may be nil). If the
+// template fails, the raw text is sent and a warning event is emitted.
+//
+// Usage:
+//
+//	agentcore.WithSystemPromptTemplate(
+//	    "You are helping {{.User}}. Today is {{.Now.Format \"2006-01-02\"}}. Tools: {{join .Tools \", \"}}.",
+//	    func() map[string]any { return map[string]any{"User": currentUser()} },
+//	)
+func WithSystemPromptTemplate(tmpl string, vars func() map[string]any) AgentOption {
+	return func(a *Agent) {
+		a.systemPrompt = tmpl
+		a.promptTemplate = newPromptTemplate(tmpl, vars)
+	}
+}
+
 // WithTools sets the tool list.
 func WithTools(tools ...Tool) AgentOption {
 	return func(a *Agent) { a.tools = tools }
@@ -108,12 +126,82 @@ func WithSessionID(id string) AgentOption {
 	return func(a *Agent) { a.sessionID = id }
 }
 
+// WithSeed sets a sampling seed passed to the model on every LLM call.
+// Models that honor it return near-deterministic output; the litellm
+// adapters ignore it because litellm exposes no seed parameter. Use
+// replay.Recorder for fully reproducible runs.
+func WithSeed(seed int) AgentOption {
+	return func(a *Agent) { a.seed = &seed }
+}
+
+// WithBudget caps the tokens and USD spent per run. The loop stops with
+// ErrBudgetExceeded before an LLM call that would exceed either limit.
+func WithBudget(b Budget) AgentOption {
+	return func(a *Agent) { a.budget = &b }
+}
+
 // WithMiddlewares sets tool execution middlewares.
 // Each middleware wraps the tool.Execute call. First middleware is outermost.
 func WithMiddlewares(mw ...ToolMiddleware) AgentOption {
 	return func(a *Agent) { a.middlewares = mw }
 }
 
+// WithInputGuard adds a guard run over each user message before it reaches
+// the model. Guards run in the order added; an error aborts the run with a
+// GuardrailError.
+func WithInputGuard(g Guard) AgentOption {
+	return func(a *Agent) { a.inputGuards = append(a.inputGuards, g) }
+}
+
+// WithOutputGuard adds a guard run over assistant text before it is emitted
+// or stored. Streamed text is held until the message completes so that
+// subscribers only ever see guarded content.
+func WithOutputGuard(g Guard) AgentOption {
+	return func(a *Agent) { a.outputGuards = append(a.outputGuards, g) }
+}
+
+// WithToolResultProcessor rewrites successful tool results before they enter
+// the conversation, e.g. TruncateToolResults. Events keep the full result.
+func WithToolResultProcessor(fn ToolResultProcessor) AgentOption {
+	return func(a *Agent) { a.toolResultProc = fn }
+}
+
+// WithRequestLogger sets a hook that receives the exact messages, tool specs,
+// and call options sent on every LLM call, with the response or error. API
+// keys are redacted. Use it to debug prompts and tool schemas.
+//
+// Usage:
+//
+//	agentcore.WithRequestLogger(func(ctx context.Context, ex agentcore.LLMExchange) {
+//	    b, _ := json.MarshalIndent(ex.Request, "", "  ")
+//	    log.Printf("LLM call (%s, err=%v):\n%s", ex.Duration, ex.Err, b)
+//	})
+func WithRequestLogger(fn RequestLogger) AgentOption {
+	return func(a *Agent) { a.requestLogger = fn }
+}
+
+// WithToolFilter narrows the tools offered to the model per call without
+// rebuilding the agent. A call to a filtered-out tool is denied with a
+// tool_denied event, even if the model names it anyway.
+//
+// Usage:
+//
+//	agentcore.WithToolFilter(func(ctx context.Context, name string) bool {
+//	    return name != "bash" || session.Trusted()
+//	})
+func WithToolFilter(fn ToolFilter) AgentOption {
+	return func(a *Agent) { a.toolFilter = fn }
+}
+
+// WithToolArgRepair enables repair of malformed tool call arguments: trailing
+// commas, unquoted keys, single-quoted strings, Python literals, code fences,
+// and unclosed brackets from truncated output. Arguments that still fail to
+// parse are returned to the model with the parse error and the tool's schema
+// so it can resend the call. Off by default.
+func WithToolArgRepair(enabled bool) AgentOption {
+	return func(a *Agent) { a.repairToolArgs = enabled }
+}
+
 // WithContextPipeline sets both TransformContext and ConvertToLLM in one call.
 // This is the recommended way to configure context compaction:
 //
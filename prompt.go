@@ -0,0 +1,45 @@
+package agentcore
+
+import (
+	"strings"
+	"text/template"
+	"time"
+)
+
+// promptTemplate renders a text/template system prompt before each LLM call.
+type promptTemplate struct {
+	tmpl     *template.Template
+	parseErr error
+	vars     func() map[string]any
+}
+
+func newPromptTemplate(raw string, vars func() map[string]any) *promptTemplate {
+	tmpl, err := template.New("system_prompt").
+		Funcs(template.FuncMap{"join": strings.Join}).
+		Option("missingkey=zero").
+		Parse(raw)
+	return &promptTemplate{tmpl: tmpl, parseErr: err, vars: vars}
+}
+
+// render executes the template with the built-in fields Now and Tools (tool
+// names) plus the caller's variables, which take precedence.
+func (p *promptTemplate) render(tools []Tool) (string, error) {
+	if p.parseErr != nil {
+		return "", p.parseErr
+	}
+	names := make([]string, len(tools))
+	for i, t := range tools {
+		names[i] = t.Name()
+	}
+	data := map[string]any{"Now": time.Now(), "Tools": names}
+	if p.vars != nil {
+		for k, v := range p.vars() {
+			data[k] = v
+		}
+	}
+	var sb strings.Builder
+	if err := p.tmpl.Execute(&sb, data); err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
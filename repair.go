@@ -0,0 +1,169 @@
+package agentcore
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// repairJSON fixes the malformed JSON models most often emit in tool call
+// arguments: markdown code fences, trailing commas, unquoted keys,
+// single-quoted strings, Python literals (True/False/None), and unclosed
+// strings or brackets from truncated output. It returns the input and false
+// when the result is still not valid JSON.
+func repairJSON(raw json.RawMessage) (json.RawMessage, bool) {
+	s := extractJSON(string(raw))
+	if s == "" {
+		return json.RawMessage("{}"), true
+	}
+
+	var (
+		out   strings.Builder
+		stack []byte // expected closers
+	)
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == '"' || c == '\'':
+			end, closed := writeQuoted(&out, s, i)
+			if !closed {
+				out.WriteByte('"')
+			}
+			i = end
+		case c == '{' || c == '[':
+			closer := byte('}')
+			if c == '[' {
+				closer = ']'
+			}
+			stack = append(stack, closer)
+			out.WriteByte(c)
+			i++
+		case c == '}' || c == ']':
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+			out.WriteByte(c)
+			i++
+		case c == ',':
+			// Drop trailing commas before a closer or end of input.
+			j := skipSpace(s, i+1)
+			if j < len(s) && s[j] != '}' && s[j] != ']' {
+				out.WriteByte(c)
+			}
+			i++
+		case c == '-' || (c >= '0' && c <= '9'):
+			j := i + 1
+			for j < len(s) && strings.IndexByte("0123456789.eE+-", s[j]) >= 0 {
+				j++
+			}
+			out.WriteString(s[i:j])
+			i = j
+		case isIdentStart(c):
+			j := i + 1
+			for j < len(s) && isIdentPart(s[j]) {
+				j++
+			}
+			word := s[i:j]
+			if k := skipSpace(s, j); k < len(s) && s[k] == ':' {
+				out.WriteString(fmt.Sprintf("%q", word)) // unquoted key
+			} else {
+				switch word {
+				case "true", "True":
+					out.WriteString("true")
+				case "false", "False":
+					out.WriteString("false")
+				case "null", "None":
+					out.WriteString("null")
+				default:
+					out.WriteString(fmt.Sprintf("%q", word))
+				}
+			}
+			i = j
+		default:
+			out.WriteByte(c)
+			i++
+		}
+	}
+	// Strip a dangling comma or colon left by truncation, then close brackets.
+	repaired := strings.TrimRight(strings.TrimSpace(out.String()), ",")
+	if strings.HasSuffix(repaired, ":") {
+		repaired += "null"
+	}
+	for i := len(stack) - 1; i >= 0; i-- {
+		repaired += string(stack[i])
+	}
+
+	if !json.Valid([]byte(repaired)) {
+		return raw, false
+	}
+	return json.RawMessage(repaired), true
+}
+
+// writeQuoted copies the string literal starting at s[start] to out as a
+// double-quoted JSON string. It returns the index after the closing quote and
+// whether the literal was terminated.
+func writeQuoted(out *strings.Builder, s string, start int) (int, bool) {
+	quote := s[start]
+	out.WriteByte('"')
+	for i := start + 1; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '\\':
+			if i+1 == len(s) {
+				continue // dangling escape from truncation
+			}
+			if quote == '\'' && s[i+1] == '\'' {
+				out.WriteByte('\'') // \' is not a JSON escape
+			} else {
+				out.WriteByte(c)
+				out.WriteByte(s[i+1])
+			}
+			i++
+		case c == quote:
+			out.WriteByte('"')
+			return i + 1, true
+		case c == '"':
+			out.WriteString(`\"`) // only reachable inside single quotes
+		case c == '\n':
+			out.WriteString(`\n`)
+		case c == '\t':
+			out.WriteString(`\t`)
+		case c == '\r':
+			out.WriteString(`\r`)
+		default:
+			out.WriteByte(c)
+		}
+	}
+	return len(s), false
+}
+
+func skipSpace(s string, i int) int {
+	for i < len(s) && (s[i] == ' ' || s[i] == '\t' || s[i] == '\n' || s[i] == '\r') {
+		i++
+	}
+	return i
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || c == '$' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isIdentPart(c byte) bool {
+	return isIdentStart(c) || (c >= '0' && c <= '9') || c == '-'
+}
+
+// argParseError is returned to the model when arguments remain invalid after
+// repair. It carries the parse error and the tool's schema so the next turn
+// can correct the call.
+func argParseError(tool Tool, args json.RawMessage) error {
+	var parseErr error
+	var v any
+	if err := json.Unmarshal(args, &v); err != nil {
+		parseErr = err
+	}
+	schema, _ := json.Marshal(tool.Schema())
+	return &ValidationError{
+		Tool:   tool.Name(),
+		Reason: fmt.Sprintf("invalid JSON arguments (%v); resend the call with arguments matching this schema: %s", parseErr, schema),
+	}
+}
@@ -0,0 +1,60 @@
+package agentcore
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestRepairJSON(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string // expected JSON value, compared after decoding
+	}{
+		{"valid", `{"a":1}`, `{"a":1}`},
+		{"trailing comma in object", `{"a":1,}`, `{"a":1}`},
+		{"trailing comma in array", `{"a":[1,2,],}`, `{"a":[1,2]}`},
+		{"unclosed brace", `{"a":1`, `{"a":1}`},
+		{"unclosed nested", `{"a":{"b":[1,2`, `{"a":{"b":[1,2]}}`},
+		{"unclosed string", `{"path":"/tmp/fo`, `{"path":"/tmp/fo"}`},
+		{"dangling key", `{"a":1,"b":`, `{"a":1,"b":null}`},
+		{"fenced", "```json\n{\"a\":1}\n```", `{"a":1}`},
+		{"fenced without tag", "```\n{\"a\":1,}\n```", `{"a":1}`},
+		{"unquoted keys", `{a: 1, b_c: "x"}`, `{"a":1,"b_c":"x"}`},
+		{"single quotes", `{'a': 'it\'s "q"'}`, `{"a":"it's \"q\""}`},
+		{"python literals", `{"a": True, "b": False, "c": None}`, `{"a":true,"b":false,"c":null}`},
+		{"raw newline in string", "{\"a\":\"x\ny\"}", `{"a":"x\ny"}`},
+		{"empty", ``, `{}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, ok := repairJSON(json.RawMessage(tt.in))
+			if !ok {
+				t.Fatalf("repairJSON(%q) failed", tt.in)
+			}
+			var got, want any
+			if err := json.Unmarshal(out, &got); err != nil {
+				t.Fatalf("repairJSON(%q) = %s, not valid JSON: %v", tt.in, out, err)
+			}
+			if err := json.Unmarshal([]byte(tt.want), &want); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Fatalf("repairJSON(%q) = %s, want %s", tt.in, out, tt.want)
+			}
+		})
+	}
+}
+
+func TestRepairJSONUnrepairable(t *testing.T) {
+	for _, in := range []string{`{"a" 1}`, `{"a":1}}`, `{"a":@}`} {
+		out, ok := repairJSON(json.RawMessage(in))
+		if ok {
+			t.Errorf("repairJSON(%q) = %s, want failure", in, out)
+		}
+		if string(out) != in {
+			t.Errorf("repairJSON(%q) returned %s, want the input unchanged", in, out)
+		}
+	}
+}
@@ -0,0 +1,209 @@
+// Package replay records agent runs to a cassette file and replays them
+// without calling the LLM or executing tools, for deterministic tests of
+// multi-step agent logic.
+//
+// Record once against the real provider:
+//
+//	rec := replay.NewRecorder()
+//	agent := agentcore.NewAgent(
+//	    agentcore.WithModel(rec.Model(model)),
+//	    agentcore.WithTools(tools...),
+//	    agentcore.WithMiddlewares(rec.Middleware()),
+//	)
+//	// ... run the agent ...
+//	rec.Save("testdata/search.cassette.json")
+//
+// Replay in tests:
+//
+//	player, _ := replay.Load("testdata/search.cassette.json")
+//	agent := agentcore.NewAgent(
+//	    agentcore.WithModel(player.Model()),
+//	    agentcore.WithTools(tools...),
+//	    agentcore.WithMiddlewares(player.Middleware()),
+//	)
+package replay
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/voocel/agentcore"
+)
+
+// Kind distinguishes recorded interaction types.
+type Kind string
+
+const (
+	KindLLM  Kind = "llm"
+	KindTool Kind = "tool"
+)
+
+// Interaction is one recorded request/response pair.
+type Interaction struct {
+	Kind     Kind            `json:"kind"`
+	Hash     string          `json:"hash"`
+	Request  json.RawMessage `json:"request"`
+	Response json.RawMessage `json:"response,omitempty"`
+	Error    string          `json:"error,omitempty"`
+}
+
+// Cassette is the on-disk recording format.
+type Cassette struct {
+	Version      int           `json:"version"`
+	Interactions []Interaction `json:"interactions"`
+}
+
+const cassetteVersion = 1
+
+// LoadCassette reads a cassette from path.
+func LoadCassette(path string) (*Cassette, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read cassette: %w", err)
+	}
+	var c Cassette
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("parse cassette %s: %w", path, err)
+	}
+	if c.Version != cassetteVersion {
+		return nil, fmt.Errorf("cassette %s: unsupported version %d", path, c.Version)
+	}
+	return &c, nil
+}
+
+// Save writes the cassette to path as indented JSON.
+func (c *Cassette) Save(path string) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal cassette: %w", err)
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// ---------------------------------------------------------------------------
+// Request normalization
+// ---------------------------------------------------------------------------
+
+// llmRequest is the hashed form of an LLM call. Timestamps, usage, and
+// per-call options (API keys, sessions) are excluded so they don't break matching.
+type llmRequest struct {
+	Messages []llmMessage         `json:"messages"`
+	Tools    []agentcore.ToolSpec `json:"tools,omitempty"`
+}
+
+type llmMessage struct {
+	Role     agentcore.Role           `json:"role"`
+	Content  []agentcore.ContentBlock `json:"content"`
+	Metadata map[string]any           `json:"metadata,omitempty"`
+}
+
+type toolRequest struct {
+	Name string `json:"name"`
+	Args any    `json:"args"`
+}
+
+type toolResponse struct {
+	Result json.RawMessage `json:"result"`
+}
+
+func newLLMRequest(messages []agentcore.Message, tools []agentcore.ToolSpec) llmRequest {
+	req := llmRequest{Messages: make([]llmMessage, len(messages)), Tools: tools}
+	for i, m := range messages {
+		req.Messages[i] = llmMessage{Role: m.Role, Content: m.Content, Metadata: m.Metadata}
+	}
+	return req
+}
+
+func newToolRequest(call agentcore.ToolCall) toolRequest {
+	var args any
+	if err := json.Unmarshal(call.Args, &args); err != nil {
+		args = string(call.Args)
+	}
+	return toolRequest{Name: call.Name, Args: args}
+}
+
+// encodeRequest returns the canonical JSON of a request and its hash.
+// Re-marshaling through map[string]any sorts keys at every level.
+func encodeRequest(v any) (json.RawMessage, string, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, "", err
+	}
+	var generic any
+	if err := json.Unmarshal(data, &generic); err != nil {
+		return nil, "", err
+	}
+	canon, err := json.Marshal(generic)
+	if err != nil {
+		return nil, "", err
+	}
+	sum := sha256.Sum256(canon)
+	return canon, hex.EncodeToString(sum[:]), nil
+}
+
+// ---------------------------------------------------------------------------
+// Mismatch diff
+// ---------------------------------------------------------------------------
+
+const maxDiffLines = 40
+
+// diffJSON renders a line diff of two JSON documents ("-" expected, "+" actual).
+func diffJSON(expected, actual json.RawMessage) string {
+	a := indentLines(expected)
+	b := indentLines(actual)
+
+	// Longest common subsequence table
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var sb strings.Builder
+	lines := 0
+	write := func(prefix, line string) {
+		if lines < maxDiffLines {
+			sb.WriteString(prefix + line + "\n")
+		}
+		lines++
+	}
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			i++
+			j++
+		case i < len(a) && (j == len(b) || lcs[i+1][j] >= lcs[i][j+1]):
+			write("- ", a[i])
+			i++
+		default:
+			write("+ ", b[j])
+			j++
+		}
+	}
+	if lines > maxDiffLines {
+		fmt.Fprintf(&sb, "... %d more changed lines\n", lines-maxDiffLines)
+	}
+	return sb.String()
+}
+
+func indentLines(raw json.RawMessage) []string {
+	var v any
+	if err := json.Unmarshal(raw, &v); err != nil {
+		return strings.Split(string(raw), "\n")
+	}
+	out, _ := json.MarshalIndent(v, "", "  ")
+	return strings.Split(string(out), "\n")
+}
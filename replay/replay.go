@@ -0,0 +1,273 @@
+package replay
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/voocel/agentcore"
+)
+
+// ErrMismatch is returned during replay when a request has no matching
+// recorded interaction. The wrapped message includes a diff against the
+// next unplayed recording of the same kind.
+var ErrMismatch = errors.New("replay: request does not match cassette")
+
+// ---------------------------------------------------------------------------
+// Recorder
+// ---------------------------------------------------------------------------
+
+// Recorder captures LLM calls and tool results into a Cassette.
+type Recorder struct {
+	mu       sync.Mutex
+	cassette Cassette
+}
+
+// NewRecorder creates an empty recorder.
+func NewRecorder() *Recorder {
+	return &Recorder{cassette: Cassette{Version: cassetteVersion}}
+}
+
+// Cassette returns a copy of everything recorded so far.
+func (r *Recorder) Cassette() *Cassette {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	c := Cassette{Version: r.cassette.Version}
+	c.Interactions = append(c.Interactions, r.cassette.Interactions...)
+	return &c
+}
+
+// Save writes the recording to path.
+func (r *Recorder) Save(path string) error {
+	return r.Cassette().Save(path)
+}
+
+func (r *Recorder) record(kind Kind, req any, resp any, callErr error) {
+	canon, hash, err := encodeRequest(req)
+	if err != nil {
+		return
+	}
+	in := Interaction{Kind: kind, Hash: hash, Request: canon}
+	if callErr != nil {
+		in.Error = callErr.Error()
+	}
+	// Failed tool calls keep any partial output they returned with the error.
+	if callErr == nil || kind == KindTool {
+		if data, err := json.Marshal(resp); err == nil {
+			in.Response = data
+		}
+	}
+	r.mu.Lock()
+	r.cassette.Interactions = append(r.cassette.Interactions, in)
+	r.mu.Unlock()
+}
+
+// Model wraps a ChatModel so every call is recorded.
+func (r *Recorder) Model(m agentcore.ChatModel) agentcore.ChatModel {
+	return &recordingModel{inner: m, rec: r}
+}
+
+// Middleware returns a ToolMiddleware that records every tool result.
+func (r *Recorder) Middleware() agentcore.ToolMiddleware {
+	return func(ctx context.Context, call agentcore.ToolCall, next agentcore.ToolExecuteFunc) (json.RawMessage, error) {
+		out, err := next(ctx, call.Args)
+		r.record(KindTool, newToolRequest(call), toolResponse{Result: out}, err)
+		return out, err
+	}
+}
+
+type recordingModel struct {
+	inner agentcore.ChatModel
+	rec   *Recorder
+}
+
+func (m *recordingModel) SupportsTools() bool { return m.inner.SupportsTools() }
+
+func (m *recordingModel) Generate(ctx context.Context, messages []agentcore.Message, tools []agentcore.ToolSpec, opts ...agentcore.CallOption) (*agentcore.LLMResponse, error) {
+	resp, err := m.inner.Generate(ctx, messages, tools, opts...)
+	var msg agentcore.Message
+	if resp != nil {
+		msg = resp.Message
+	}
+	m.rec.record(KindLLM, newLLMRequest(messages, tools), msg, err)
+	return resp, err
+}
+
+// GenerateStream forwards events unchanged and records the final message.
+func (m *recordingModel) GenerateStream(ctx context.Context, messages []agentcore.Message, tools []agentcore.ToolSpec, opts ...agentcore.CallOption) (<-chan agentcore.StreamEvent, error) {
+	src, err := m.inner.GenerateStream(ctx, messages, tools, opts...)
+	if err != nil {
+		return nil, err // the loop falls back to Generate, which records
+	}
+	req := newLLMRequest(messages, tools)
+	out := make(chan agentcore.StreamEvent, 100)
+	go func() {
+		defer close(out)
+		var (
+			last      agentcore.Message
+			streamErr error
+			recorded  bool
+		)
+		for ev := range src {
+			switch ev.Type {
+			case agentcore.StreamEventDone:
+				m.rec.record(KindLLM, req, ev.Message, nil)
+				recorded = true
+			case agentcore.StreamEventError:
+				streamErr = ev.Err
+			default:
+				last = ev.Message
+			}
+			out <- ev
+		}
+		if !recorded {
+			m.rec.record(KindLLM, req, last, streamErr)
+		}
+	}()
+	return out, nil
+}
+
+// ---------------------------------------------------------------------------
+// Player
+// ---------------------------------------------------------------------------
+
+// Player serves recorded responses in place of a real model and real tools.
+// Requests are matched by hash against unplayed interactions of the same
+// kind; each recording is served at most once.
+type Player struct {
+	mu       sync.Mutex
+	cassette *Cassette
+	played   []bool
+}
+
+// NewPlayer creates a player for an in-memory cassette.
+func NewPlayer(c *Cassette) *Player {
+	return &Player{cassette: c, played: make([]bool, len(c.Interactions))}
+}
+
+// Load reads a cassette file and returns a player for it.
+func Load(path string) (*Player, error) {
+	c, err := LoadCassette(path)
+	if err != nil {
+		return nil, err
+	}
+	return NewPlayer(c), nil
+}
+
+// Remaining returns the number of recorded interactions not yet served.
+// Tests can assert it is zero to catch runs that stopped early.
+func (p *Player) Remaining() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	n := 0
+	for _, done := range p.played {
+		if !done {
+			n++
+		}
+	}
+	return n
+}
+
+// next finds and consumes the first unplayed interaction matching req.
+func (p *Player) next(kind Kind, req any) (Interaction, error) {
+	canon, hash, err := encodeRequest(req)
+	if err != nil {
+		return Interaction{}, fmt.Errorf("replay: encode request: %w", err)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	expected := -1
+	for i, in := range p.cassette.Interactions {
+		if p.played[i] || in.Kind != kind {
+			continue
+		}
+		if expected < 0 {
+			expected = i
+		}
+		if in.Hash == hash {
+			p.played[i] = true
+			return in, nil
+		}
+	}
+	if expected < 0 {
+		return Interaction{}, fmt.Errorf("%w: unexpected %s request, no recordings left:\n%s", ErrMismatch, kind, canon)
+	}
+	return Interaction{}, fmt.Errorf("%w: %s request differs from recording #%d:\n%s",
+		ErrMismatch, kind, expected, diffJSON(p.cassette.Interactions[expected].Request, canon))
+}
+
+// Model returns a ChatModel that serves recorded LLM responses.
+func (p *Player) Model() agentcore.ChatModel { return &replayModel{player: p} }
+
+// Middleware returns a ToolMiddleware that serves recorded tool results
+// without executing the tool.
+func (p *Player) Middleware() agentcore.ToolMiddleware {
+	return func(_ context.Context, call agentcore.ToolCall, _ agentcore.ToolExecuteFunc) (json.RawMessage, error) {
+		in, err := p.next(KindTool, newToolRequest(call))
+		if err != nil {
+			return nil, err
+		}
+		var resp toolResponse
+		if len(in.Response) > 0 {
+			if err := json.Unmarshal(in.Response, &resp); err != nil {
+				return nil, fmt.Errorf("replay: decode tool response: %w", err)
+			}
+		}
+		if in.Error != "" {
+			return resp.Result, errors.New(in.Error) // with any partial output
+		}
+		return resp.Result, nil
+	}
+}
+
+type replayModel struct {
+	player *Player
+}
+
+func (m *replayModel) SupportsTools() bool { return true }
+
+func (m *replayModel) Generate(_ context.Context, messages []agentcore.Message, tools []agentcore.ToolSpec, _ ...agentcore.CallOption) (*agentcore.LLMResponse, error) {
+	in, err := m.player.next(KindLLM, newLLMRequest(messages, tools))
+	if err != nil {
+		return nil, err
+	}
+	msg, err := decodeLLMResponse(in)
+	if err != nil {
+		return nil, err
+	}
+	return &agentcore.LLMResponse{Message: msg}, nil
+}
+
+// GenerateStream replays the recorded message as one delta per content block.
+// A recorded failure is delivered as a stream error event: returning it from
+// GenerateStream would make the loop fall back to Generate, which would
+// consume the next recording.
+func (m *replayModel) GenerateStream(_ context.Context, messages []agentcore.Message, tools []agentcore.ToolSpec, _ ...agentcore.CallOption) (<-chan agentcore.StreamEvent, error) {
+	in, err := m.player.next(KindLLM, newLLMRequest(messages, tools))
+	if err != nil {
+		return nil, err // nothing consumed; the Generate fallback reports it
+	}
+	msg, err := decodeLLMResponse(in)
+	if err != nil {
+		ch := make(chan agentcore.StreamEvent, 1)
+		ch <- agentcore.StreamEvent{Type: agentcore.StreamEventError, Err: err}
+		close(ch)
+		return ch, nil
+	}
+	return agentcore.StreamMessage(msg), nil
+}
+
+// decodeLLMResponse returns the recorded message, or the recorded error.
+func decodeLLMResponse(in Interaction) (agentcore.Message, error) {
+	if in.Error != "" {
+		return agentcore.Message{}, errors.New(in.Error)
+	}
+	var msg agentcore.Message
+	if err := json.Unmarshal(in.Response, &msg); err != nil {
+		return agentcore.Message{}, fmt.Errorf("replay: decode llm response: %w", err)
+	}
+	return msg, nil
+}
@@ -0,0 +1,191 @@
+package replay
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/voocel/agentcore"
+	"github.com/voocel/agentcore/llm/llmtest"
+)
+
+// run prompts agent and returns its final messages and the run error, if any.
+func run(t *testing.T, agent *agentcore.Agent, input string) ([]agentcore.AgentMessage, error) {
+	t.Helper()
+	var (
+		mu     sync.Mutex
+		runErr error
+	)
+	unsubscribe := agent.Subscribe(func(ev agentcore.Event) {
+		if ev.Type == agentcore.EventError {
+			mu.Lock()
+			runErr = ev.Err
+			mu.Unlock()
+		}
+	})
+	defer unsubscribe()
+	if err := agent.Prompt(input); err != nil {
+		t.Fatal(err)
+	}
+	agent.WaitForIdle()
+	mu.Lock()
+	defer mu.Unlock()
+	return agent.Messages(), runErr
+}
+
+// recordLookup records a run in which the model calls lookup once and then
+// answers, and returns the cassette.
+func recordLookup(t *testing.T) *Cassette {
+	t.Helper()
+	model := llmtest.NewModel(
+		llmtest.ToolCalls(agentcore.ToolCall{ID: "1", Name: "lookup", Args: json.RawMessage(`{"q":"go"}`)}),
+		llmtest.Text("Go is a language."),
+	)
+	lookup := llmtest.NewTool("lookup", func(context.Context, json.RawMessage) (json.RawMessage, error) {
+		return json.RawMessage(`"a language"`), nil
+	})
+	rec := NewRecorder()
+	agent := agentcore.NewAgent(
+		agentcore.WithModel(rec.Model(model)),
+		agentcore.WithTools(lookup),
+		agentcore.WithMiddlewares(rec.Middleware()),
+	)
+	if _, err := run(t, agent, "what is go?"); err != nil {
+		t.Fatalf("recording run: %v", err)
+	}
+	return rec.Cassette()
+}
+
+// replayAgent returns an agent served by player whose lookup tool fails the
+// test if it is ever executed.
+func replayAgent(t *testing.T, player *Player) *agentcore.Agent {
+	lookup := llmtest.NewTool("lookup", func(context.Context, json.RawMessage) (json.RawMessage, error) {
+		t.Error("tool executed during replay")
+		return nil, nil
+	})
+	return agentcore.NewAgent(
+		agentcore.WithModel(player.Model()),
+		agentcore.WithTools(lookup),
+		agentcore.WithMiddlewares(player.Middleware()),
+		agentcore.WithMaxRetries(0),
+	)
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	cassette := recordLookup(t)
+	var kinds []Kind
+	for _, in := range cassette.Interactions {
+		kinds = append(kinds, in.Kind)
+	}
+	if len(kinds) != 3 || kinds[0] != KindLLM || kinds[1] != KindTool || kinds[2] != KindLLM {
+		t.Fatalf("recorded kinds = %v, want [llm tool llm]", kinds)
+	}
+
+	path := filepath.Join(t.TempDir(), "run.cassette.json")
+	if err := cassette.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	player, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	msgs, err := run(t, replayAgent(t, player), "what is go?")
+	if err != nil {
+		t.Fatalf("replay run: %v", err)
+	}
+	if got := msgs[len(msgs)-1].(agentcore.Message).TextContent(); got != "Go is a language." {
+		t.Fatalf("final answer = %q, want the recorded one", got)
+	}
+	var toolResult string
+	for _, m := range msgs {
+		if msg, ok := m.(agentcore.Message); ok && msg.Role == agentcore.RoleTool {
+			toolResult = msg.TextContent()
+		}
+	}
+	if !strings.Contains(toolResult, "a language") {
+		t.Errorf("tool result = %q, want the recorded result", toolResult)
+	}
+	if n := player.Remaining(); n != 0 {
+		t.Fatalf("%d recordings left unplayed", n)
+	}
+}
+
+func TestReplayMismatchDiff(t *testing.T) {
+	player := NewPlayer(recordLookup(t))
+
+	_, err := run(t, replayAgent(t, player), "what is rust?")
+	if !errors.Is(err, ErrMismatch) {
+		t.Fatalf("err = %v, want ErrMismatch", err)
+	}
+	var removed, added bool
+	for _, line := range strings.Split(err.Error(), "\n") {
+		removed = removed || strings.HasPrefix(line, "- ") && strings.Contains(line, "what is go?")
+		added = added || strings.HasPrefix(line, "+ ") && strings.Contains(line, "what is rust?")
+	}
+	if !removed || !added {
+		t.Fatalf("mismatch error lacks an expected/actual diff:\n%v", err)
+	}
+	if n := player.Remaining(); n != 3 {
+		t.Fatalf("Remaining = %d, want nothing consumed by a mismatch", n)
+	}
+}
+
+func TestReplayNoRecordingsLeft(t *testing.T) {
+	player := NewPlayer(&Cassette{Version: cassetteVersion})
+	_, err := player.Model().Generate(context.Background(), []agentcore.Message{agentcore.UserMsg("hi")}, nil)
+	if !errors.Is(err, ErrMismatch) || !strings.Contains(err.Error(), "no recordings left") {
+		t.Fatalf("err = %v, want ErrMismatch with no recordings left", err)
+	}
+}
+
+func TestReplayToolResults(t *testing.T) {
+	call := agentcore.ToolCall{ID: "1", Name: "fetch", Args: json.RawMessage(`{"url":"a", "n": 1}`)}
+	boom := errors.New("boom")
+	rec := NewRecorder()
+	record := rec.Middleware()
+	record(context.Background(), call, func(context.Context, json.RawMessage) (json.RawMessage, error) {
+		return json.RawMessage(`{"pages":1}`), boom
+	})
+
+	// Argument key order does not affect matching.
+	replayed := agentcore.ToolCall{ID: "2", Name: "fetch", Args: json.RawMessage(`{"n":1,"url":"a"}`)}
+	play := NewPlayer(rec.Cassette()).Middleware()
+	out, err := play(context.Background(), replayed, func(context.Context, json.RawMessage) (json.RawMessage, error) {
+		t.Fatal("tool executed during replay")
+		return nil, nil
+	})
+	if err == nil || err.Error() != "boom" {
+		t.Fatalf("err = %v, want the recorded error", err)
+	}
+	if string(out) != `{"pages":1}` {
+		t.Fatalf("output = %s, want the recorded partial output", out)
+	}
+
+	_, err = play(context.Background(), replayed, nil)
+	if !errors.Is(err, ErrMismatch) {
+		t.Fatalf("second replay err = %v, want ErrMismatch (each recording plays once)", err)
+	}
+}
+
+func TestReplayRecordedModelError(t *testing.T) {
+	msgs := []agentcore.Message{agentcore.UserMsg("hi")}
+	rec := NewRecorder()
+	model := rec.Model(llmtest.NewModel(llmtest.Error(errors.New("overloaded")), llmtest.Text("ok")))
+	model.Generate(context.Background(), msgs, nil)
+	model.Generate(context.Background(), msgs, nil)
+
+	player := NewPlayer(rec.Cassette())
+	agent := agentcore.NewAgent(agentcore.WithModel(player.Model()), agentcore.WithMaxRetries(0))
+	_, err := run(t, agent, "hi")
+	if err == nil || !strings.Contains(err.Error(), "overloaded") {
+		t.Fatalf("err = %v, want the recorded error", err)
+	}
+	if n := player.Remaining(); n != 1 {
+		t.Fatalf("Remaining = %d, want only the failed call consumed", n)
+	}
+}
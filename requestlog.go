@@ -0,0 +1,41 @@
+package agentcore
+
+import (
+	"context"
+	"time"
+)
+
+// redactedKey replaces API keys in logged call configs.
+const redactedKey = "[REDACTED]"
+
+// LLMExchange is one model call made by the agent loop: the exact request,
+// the resolved call options, and the final response or error. Retries and
+// the follow-up calls after tool results each produce their own exchange.
+type LLMExchange struct {
+	Request  LLMRequest // messages (including the system prompt) and tool specs
+	Config   CallConfig // resolved call options, APIKey redacted
+	Response Message    // final assistant message after output guards, zero on error
+	Err      error
+	Duration time.Duration
+}
+
+// RequestLogger receives every LLMExchange. It runs on the loop goroutine,
+// so slow loggers delay the run.
+type RequestLogger func(ctx context.Context, ex LLMExchange)
+
+func logLLMCall(ctx context.Context, logger RequestLogger, req LLMRequest, opts []CallOption, resp Message, err error, start time.Time) {
+	if logger == nil {
+		return
+	}
+	cfg := ResolveCallConfig(opts)
+	if cfg.APIKey != "" {
+		cfg.APIKey = redactedKey
+	}
+	logger(ctx, LLMExchange{
+		Request:  req,
+		Config:   cfg,
+		Response: resp,
+		Err:      err,
+		Duration: time.Since(start),
+	})
+}
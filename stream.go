@@ -1,6 +1,9 @@
 package agentcore
 
-import "sync"
+import (
+	"encoding/json"
+	"sync"
+)
 
 // Collect consumes all events from the channel and returns the final messages.
 // Blocks until the channel is closed. Returns any error from EventError events.
@@ -88,3 +91,156 @@ func (s *EventStream) Result() ([]AgentMessage, error) {
 func (s *EventStream) Done() <-chan struct{} {
 	return s.done
 }
+
+// ---------------------------------------------------------------------------
+// Typed stream chunks
+// ---------------------------------------------------------------------------
+
+// StreamChunkKind identifies the kind of a StreamChunk.
+type StreamChunkKind string
+
+const (
+	ChunkTextDelta     StreamChunkKind = "text_delta"
+	ChunkThinkingDelta StreamChunkKind = "thinking_delta"
+	ChunkToolCallStart StreamChunkKind = "tool_call_start"
+	ChunkToolProgress  StreamChunkKind = "tool_progress"
+	ChunkToolResult    StreamChunkKind = "tool_result"
+	ChunkFinal         StreamChunkKind = "final"
+	ChunkError         StreamChunkKind = "error"
+)
+
+// StreamChunk is a simplified, UI-oriented view of the agent event stream.
+// Only the fields relevant to Kind are populated.
+type StreamChunk struct {
+	Kind      StreamChunkKind
+	Text      string          // text_delta, thinking_delta; final answer text for final
+	ToolID    string          // tool_*
+	Tool      string          // tool_*
+	ToolLabel string          // tool_*
+	Args      json.RawMessage // tool_call_start
+	Result    json.RawMessage // tool_progress, tool_result
+	IsError   bool            // tool_result
+	Messages  []AgentMessage  // final: messages added during the run
+	Err       error           // error
+}
+
+// ToStreamChunk maps an Event to a StreamChunk.
+// Returns false for events that have no chunk representation.
+func ToStreamChunk(ev Event) (StreamChunk, bool) {
+	switch ev.Type {
+	case EventMessageUpdate:
+		if ev.Message == nil || ev.Message.GetRole() != RoleAssistant || ev.Delta == "" {
+			return StreamChunk{}, false
+		}
+		switch ev.DeltaType {
+		case StreamEventTextDelta:
+			return StreamChunk{Kind: ChunkTextDelta, Text: ev.Delta}, true
+		case StreamEventThinkingDelta:
+			return StreamChunk{Kind: ChunkThinkingDelta, Text: ev.Delta}, true
+		}
+	case EventToolExecStart:
+		return StreamChunk{Kind: ChunkToolCallStart, ToolID: ev.ToolID, Tool: ev.Tool, ToolLabel: ev.ToolLabel, Args: ev.Args}, true
+	case EventToolExecUpdate:
+		return StreamChunk{Kind: ChunkToolProgress, ToolID: ev.ToolID, Tool: ev.Tool, ToolLabel: ev.ToolLabel, Result: ev.Result}, true
+	case EventToolExecEnd:
+		return StreamChunk{Kind: ChunkToolResult, ToolID: ev.ToolID, Tool: ev.Tool, ToolLabel: ev.ToolLabel, Result: ev.Result, IsError: ev.IsError}, true
+	case EventError:
+		if ev.Err != nil {
+			return StreamChunk{Kind: ChunkError, Err: ev.Err}, true
+		}
+	case EventAgentEnd:
+		chunk := StreamChunk{Kind: ChunkFinal, Messages: ev.NewMessages, Err: ev.Err}
+		for i := len(ev.NewMessages) - 1; i >= 0; i-- {
+			if ev.NewMessages[i].GetRole() == RoleAssistant {
+				chunk.Text = ev.NewMessages[i].TextContent()
+				break
+			}
+		}
+		return chunk, true
+	}
+	return StreamChunk{}, false
+}
+
+// PromptStream starts a new turn and returns typed chunks covering text
+// deltas, tool calls, tool progress/results, and the final answer.
+// The channel is closed after the final chunk of this run. Delivery never
+// blocks the agent: if the caller falls behind by more than the buffer,
+// intermediate chunks are dropped (the final chunk is always delivered).
+func (a *Agent) PromptStream(input string) (<-chan StreamChunk, error) {
+	var (
+		mu     sync.Mutex
+		closed bool
+		runID  string
+		final  StreamChunk
+	)
+	chunks := make(chan StreamChunk, 256)
+	unsubscribe := a.Subscribe(func(ev Event) {
+		mu.Lock()
+		defer mu.Unlock()
+		if closed {
+			return // late delivery after this run ended
+		}
+		if runID == "" {
+			runID = ev.RunID
+		} else if ev.RunID != runID {
+			return
+		}
+		chunk, ok := ToStreamChunk(ev)
+		if ev.Type == EventAgentEnd {
+			final, closed = chunk, true
+			close(chunks)
+			return
+		}
+		if ok {
+			select {
+			case chunks <- chunk:
+			default: // caller not draining; drop like emit
+			}
+		}
+	})
+
+	if err := a.Prompt(input); err != nil {
+		unsubscribe()
+		mu.Lock()
+		if !closed {
+			closed = true
+			close(chunks)
+		}
+		mu.Unlock()
+		return nil, err
+	}
+
+	// Only this goroutine sends on out, so only it closes it.
+	out := make(chan StreamChunk, 64)
+	go func() {
+		defer close(out)
+		for c := range chunks {
+			out <- c
+		}
+		unsubscribe()
+		mu.Lock()
+		last := final
+		mu.Unlock()
+		out <- last
+	}()
+	return out, nil
+}
+
+// ChatStream is a text-only shim over PromptStream.
+// It yields assistant text deltas and drops all other chunk kinds.
+func (a *Agent) ChatStream(input string) (<-chan string, error) {
+	chunks, err := a.PromptStream(input)
+	if err != nil {
+		return nil, err
+	}
+	out := make(chan string, 64)
+	go func() {
+		defer close(out)
+		for c := range chunks {
+			if c.Kind == ChunkTextDelta {
+				out <- c.Text
+			}
+		}
+	}()
+	return out, nil
+}
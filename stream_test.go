@@ -0,0 +1,101 @@
+package agentcore_test
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/voocel/agentcore"
+	"github.com/voocel/agentcore/llm/llmtest"
+)
+
+func TestPromptStreamDeliversFinalChunk(t *testing.T) {
+	agent := agentcore.NewAgent(agentcore.WithModel(llmtest.NewModel(llmtest.Text("hello world"))))
+
+	chunks, err := agent.PromptStream("hi")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var text strings.Builder
+	var final *agentcore.StreamChunk
+	for c := range chunks {
+		switch c.Kind {
+		case agentcore.ChunkTextDelta:
+			text.WriteString(c.Text)
+		case agentcore.ChunkFinal:
+			final = &c
+		}
+	}
+	if text.String() != "hello world" {
+		t.Errorf("deltas = %q, want %q", text.String(), "hello world")
+	}
+	if final == nil || final.Text != "hello world" {
+		t.Fatalf("final chunk = %+v", final)
+	}
+}
+
+func TestPromptStreamDoesNotBlockAgent(t *testing.T) {
+	model := llmtest.NewModel(llmtest.Text(strings.Repeat("x ", 2000)))
+	agent := agentcore.NewAgent(agentcore.WithModel(model))
+
+	chunks, err := agent.PromptStream("hi")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Nobody drains chunks while the run is in progress.
+	idle := make(chan struct{})
+	go func() {
+		agent.WaitForIdle()
+		close(idle)
+	}()
+	select {
+	case <-idle:
+	case <-time.After(5 * time.Second):
+		t.Fatal("agent blocked on an undrained PromptStream")
+	}
+
+	var last agentcore.StreamChunk
+	for c := range chunks {
+		last = c
+	}
+	if last.Kind != agentcore.ChunkFinal {
+		t.Fatalf("last chunk kind = %q, want %q", last.Kind, agentcore.ChunkFinal)
+	}
+
+	// A later run must not reach the finished stream.
+	model.Add(llmtest.Text("again"))
+	if err := agent.Prompt("again"); err != nil {
+		t.Fatal(err)
+	}
+	agent.WaitForIdle()
+}
+
+func TestStreamMessage(t *testing.T) {
+	msg := agentcore.Message{
+		Role: agentcore.RoleAssistant,
+		Content: []agentcore.ContentBlock{
+			agentcore.ThinkingBlock("hmm"),
+			agentcore.TextBlock("hi"),
+			agentcore.ToolCallBlock(agentcore.ToolCall{ID: "1", Name: "calc"}),
+		},
+		StopReason: agentcore.StopReasonToolUse,
+	}
+	var got []agentcore.StreamEventType
+	for ev := range agentcore.StreamMessage(msg) {
+		got = append(got, ev.Type)
+		if ev.Type == agentcore.StreamEventDone && ev.StopReason != msg.StopReason {
+			t.Fatalf("done stop reason = %q, want %q", ev.StopReason, msg.StopReason)
+		}
+	}
+	want := []agentcore.StreamEventType{
+		agentcore.StreamEventThinkingDelta,
+		agentcore.StreamEventTextDelta,
+		agentcore.StreamEventToolCallEnd,
+		agentcore.StreamEventDone,
+	}
+	if !slices.Equal(got, want) {
+		t.Fatalf("events = %v, want %v", got, want)
+	}
+}
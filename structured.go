@@ -0,0 +1,123 @@
+package agentcore
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// structuredOutputName identifies the response document in validation errors.
+const structuredOutputName = "structured_output"
+
+// ErrStructuredOutput is returned when the model's response still fails
+// schema validation after the repair attempt.
+var ErrStructuredOutput = errors.New("structured output failed validation")
+
+// GenerateJSON asks the model for a JSON document matching schema, validates
+// it, and unmarshals it into out. The raw response text is returned for
+// debugging, even on failure.
+//
+// The schema is sent via WithResponseSchema so providers with a native JSON
+// mode can enforce it, and is also described in a trailing instruction so
+// models without one still know the expected shape. If the first response
+// fails validation, the model is re-prompted once with the error.
+//
+// Usage:
+//
+//	var out struct{ City string `json:"city"` }
+//	raw, err := agentcore.GenerateJSON(ctx, model, msgs,
+//	    schema.Object(schema.Property("city", schema.String("City name")).Required()), &out)
+func GenerateJSON(ctx context.Context, model ChatModel, messages []Message, schema map[string]any, out any, opts ...CallOption) (string, error) {
+	if model == nil {
+		return "", fmt.Errorf("no model configured")
+	}
+	schemaJSON, err := json.Marshal(schema)
+	if err != nil {
+		return "", fmt.Errorf("marshal schema: %w", err)
+	}
+
+	msgs := make([]Message, 0, len(messages)+3)
+	msgs = append(msgs, messages...)
+	msgs = append(msgs, UserMsg(fmt.Sprintf(
+		"Respond with a single JSON document that conforms to this JSON Schema. "+
+			"Output only the JSON, with no surrounding prose or code fences.\n\nSchema:\n%s", schemaJSON)))
+	opts = append(opts, WithResponseSchema(schema))
+
+	var raw string
+	for attempt := 0; attempt < 2; attempt++ {
+		resp, err := model.Generate(ctx, msgs, nil, opts...)
+		if err != nil {
+			return raw, err
+		}
+		if err := checkEmptyResponse(resp.Message); err != nil {
+			return raw, err
+		}
+		raw = resp.Message.TextContent()
+
+		doc := extractJSON(raw)
+		verr := validateSchema(structuredOutputName, schema, json.RawMessage(doc), true)
+		if verr == nil {
+			if err := json.Unmarshal([]byte(doc), out); err != nil {
+				return raw, fmt.Errorf("%w: %v", ErrStructuredOutput, err)
+			}
+			return raw, nil
+		}
+		if attempt == 1 {
+			return raw, fmt.Errorf("%w: %v", ErrStructuredOutput, verr)
+		}
+
+		// Repair: show the model its answer and the validation error.
+		msgs = append(msgs, resp.Message, UserMsg(fmt.Sprintf(
+			"Your response was not valid: %v\nReply again with only the corrected JSON document.", verr)))
+	}
+	return raw, ErrStructuredOutput
+}
+
+// extractJSON trims whitespace and a surrounding markdown code fence, which
+// models commonly add even when told not to.
+func extractJSON(text string) string {
+	s := strings.TrimSpace(text)
+	if !strings.HasPrefix(s, "```") {
+		return s
+	}
+	s = strings.TrimPrefix(s, "```")
+	if nl := strings.IndexByte(s, '\n'); nl >= 0 {
+		s = s[nl+1:] // drop language tag line, e.g. ```json
+	}
+	s = strings.TrimSuffix(strings.TrimSpace(s), "```")
+	return strings.TrimSpace(s)
+}
+
+// ChatJSON sends input with the agent's system prompt and conversation
+// history and decodes a schema-conforming JSON response into out. Tools are
+// not offered and the conversation history is not modified.
+// Returns the raw response text alongside any error.
+func (a *Agent) ChatJSON(ctx context.Context, input string, schema map[string]any, out any) (string, error) {
+	a.mu.Lock()
+	model := a.model
+	convert := a.convertToLLM
+	if convert == nil {
+		convert = DefaultConvertToLLM
+	}
+	systemPrompt := a.systemPrompt
+	if a.promptTemplate != nil {
+		if rendered, err := a.promptTemplate.render(a.tools); err == nil {
+			systemPrompt = rendered
+		}
+	}
+	var msgs []Message
+	if systemPrompt != "" {
+		msgs = append(msgs, SystemMsg(systemPrompt))
+	}
+	msgs = append(msgs, convert(copyMessages(a.messages))...)
+	var opts []CallOption
+	if a.thinkingLevel != "" {
+		opts = append(opts, WithThinking(a.thinkingLevel))
+	}
+	a.mu.Unlock()
+
+	msgs = append(msgs, UserMsg(input))
+	return GenerateJSON(ctx, model, msgs, schema, out, opts...)
+}
@@ -0,0 +1,70 @@
+package agentcore_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/voocel/agentcore"
+	"github.com/voocel/agentcore/llm/llmtest"
+)
+
+var citySchema = map[string]any{
+	"type":       "object",
+	"properties": map[string]any{"city": map[string]any{"type": "string"}},
+	"required":   []any{"city"},
+}
+
+func TestGenerateJSONRepairsOnce(t *testing.T) {
+	model := llmtest.NewModel(
+		llmtest.Text(`{"city": 3}`),
+		llmtest.Text("```json\n{\"city\": \"Paris\"}\n```"),
+	)
+
+	var out struct{ City string }
+	raw, err := agentcore.GenerateJSON(context.Background(), model,
+		[]agentcore.Message{agentcore.UserMsg("Where is the Louvre?")}, citySchema, &out)
+	if err != nil {
+		t.Fatalf("GenerateJSON: %v", err)
+	}
+	if out.City != "Paris" || !strings.Contains(raw, "Paris") {
+		t.Fatalf("out = %+v, raw = %q; want the repaired answer", out, raw)
+	}
+
+	calls := model.Calls()
+	if len(calls) != 2 {
+		t.Fatalf("model called %d times, want 2", len(calls))
+	}
+	if calls[0].Config.ResponseSchema == nil {
+		t.Error("first call did not request native structured output")
+	}
+	repair := calls[1].Messages
+	if len(repair) < 2 {
+		t.Fatalf("repair request = %+v, want the bad answer and the validation error", repair)
+	}
+	bad, prompt := repair[len(repair)-2], repair[len(repair)-1]
+	if bad.Role != agentcore.RoleAssistant || bad.TextContent() != `{"city": 3}` {
+		t.Errorf("repair request does not replay the bad answer: %+v", bad)
+	}
+	if prompt.Role != agentcore.RoleUser || !strings.Contains(prompt.TextContent(), "city") {
+		t.Errorf("repair prompt = %q, want the validation error", prompt.TextContent())
+	}
+}
+
+func TestGenerateJSONFailsAfterRepair(t *testing.T) {
+	model := llmtest.NewModel(llmtest.Text("not json"), llmtest.Text(`{"town": "Paris"}`))
+
+	var out struct{ City string }
+	raw, err := agentcore.GenerateJSON(context.Background(), model,
+		[]agentcore.Message{agentcore.UserMsg("Where is the Louvre?")}, citySchema, &out)
+	if !errors.Is(err, agentcore.ErrStructuredOutput) {
+		t.Fatalf("err = %v, want ErrStructuredOutput", err)
+	}
+	if raw != `{"town": "Paris"}` {
+		t.Fatalf("raw = %q, want the last response", raw)
+	}
+	if model.Remaining() != 0 || len(model.Calls()) != 2 {
+		t.Fatalf("calls = %d, want exactly one repair attempt", len(model.Calls()))
+	}
+}
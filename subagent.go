@@ -4,6 +4,8 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"maps"
+	"slices"
 	"strings"
 	"sync"
 
@@ -53,15 +55,47 @@ type subagentResult struct {
 	Agent   string `json:"agent"`
 	Task    string `json:"task"`
 	Output  string `json:"output"`
+	Handoff string `json:"handoff,omitempty"` // summarized output passed to the next chain step
 	IsError bool   `json:"is_error,omitempty"`
 	Step    int    `json:"step,omitempty"`
 }
 
+// HandoffSummarizer condenses a chain step's output before it replaces
+// {previous} in the next step's task. from and to name the two agents, so a
+// summarizer can act on selected edges only and return output unchanged
+// elsewhere. Full outputs stay in the tool result.
+type HandoffSummarizer func(ctx context.Context, from, to, output string) (string, error)
+
+// SummarizeHandoff returns a HandoffSummarizer that asks model to reduce
+// outputs longer than maxWords words to their key points.
+func SummarizeHandoff(model ChatModel, maxWords int) HandoffSummarizer {
+	return func(ctx context.Context, from, to, output string) (string, error) {
+		if len(strings.Fields(output)) <= maxWords {
+			return output, nil
+		}
+		resp, err := model.Generate(ctx, []Message{
+			SystemMsg(fmt.Sprintf(
+				"Condense the output of agent %q into the key points agent %q needs to continue the task. "+
+					"Keep facts, decisions, names, and numbers; drop repetition and commentary. Use at most %d words.",
+				from, to, maxWords)),
+			UserMsg(output),
+		}, nil)
+		if err != nil {
+			return "", err
+		}
+		if err := checkEmptyResponse(resp.Message); err != nil {
+			return "", err
+		}
+		return resp.Message.TextContent(), nil
+	}
+}
+
 // SubAgentTool implements the Tool interface.
 // The main agent calls this tool to delegate tasks to specialized sub-agents
 // with isolated contexts
 type SubAgentTool struct {
-	agents map[string]SubAgentConfig
+	agents    map[string]SubAgentConfig
+	summarize HandoffSummarizer
 }
 
 // NewSubAgentTool creates a subagent tool from a set of agent configs.
@@ -73,13 +107,32 @@ func NewSubAgentTool(agents ...SubAgentConfig) *SubAgentTool {
 	return &SubAgentTool{agents: m}
 }
 
+// WithHandoffSummarizer condenses each chain step's output before it is
+// handed to the next step. If summarizing fails, the full output is passed on.
+//
+// Usage:
+//
+//	tool := agentcore.NewSubAgentTool(researcher, writer, editor).
+//	    WithHandoffSummarizer(agentcore.SummarizeHandoff(fastModel, 300))
+func (t *SubAgentTool) WithHandoffSummarizer(fn HandoffSummarizer) *SubAgentTool {
+	t.summarize = fn
+	return t
+}
+
 func (t *SubAgentTool) Name() string  { return "subagent" }
 func (t *SubAgentTool) Label() string { return "Delegate to SubAgent" }
 
+// agentNames returns the configured agent names sorted, so the tool's
+// description and schema are identical across runs (stable prompts keep
+// provider caches and seeded runs reproducible).
+func (t *SubAgentTool) agentNames() []string {
+	return slices.Sorted(maps.Keys(t.agents))
+}
+
 func (t *SubAgentTool) Description() string {
 	names := make([]string, 0, len(t.agents))
-	for _, a := range t.agents {
-		names = append(names, fmt.Sprintf("%s (%s)", a.Name, a.Description))
+	for _, name := range t.agentNames() {
+		names = append(names, fmt.Sprintf("%s (%s)", name, t.agents[name].Description))
 	}
 	return fmt.Sprintf(
 		"Delegate tasks to specialized subagents with isolated context. "+
@@ -90,10 +143,7 @@ func (t *SubAgentTool) Description() string {
 }
 
 func (t *SubAgentTool) Schema() map[string]any {
-	agentNames := make([]string, 0, len(t.agents))
-	for name := range t.agents {
-		agentNames = append(agentNames, name)
-	}
+	agentNames := t.agentNames()
 	taskItem := schema.Object(
 		schema.Property("agent", schema.Enum("Agent name", agentNames...)).Required(),
 		schema.Property("task", schema.String("Task description")).Required(),
@@ -141,13 +191,17 @@ func (t *SubAgentTool) executeSingle(ctx context.Context, agentName, task string
 }
 
 // executeChain runs sub-agents sequentially, passing each output to the next via {previous}.
+// Once ctx is canceled no further steps start.
 func (t *SubAgentTool) executeChain(ctx context.Context, chain []subagentChain) (json.RawMessage, error) {
 	var previous string
 	results := make([]subagentResult, 0, len(chain))
 
 	for i, step := range chain {
 		if ctx.Err() != nil {
-			return nil, ctx.Err()
+			return partialResult(ctx, map[string]any{
+				"error":   fmt.Sprintf("Chain canceled before step %d (%s)", i+1, step.Agent),
+				"results": results,
+			})
 		}
 
 		task := strings.ReplaceAll(step.Task, "{previous}", previous)
@@ -163,15 +217,21 @@ func (t *SubAgentTool) executeChain(ctx context.Context, chain []subagentChain)
 			result.Output = err.Error()
 			result.IsError = true
 			results = append(results, result)
-			return json.Marshal(map[string]any{
+			return partialResult(ctx, map[string]any{
 				"error":   fmt.Sprintf("Chain stopped at step %d (%s): %v", i+1, step.Agent, err),
 				"results": results,
 			})
 		}
 
 		result.Output = output
-		results = append(results, result)
 		previous = output
+		if t.summarize != nil && i+1 < len(chain) {
+			if handoff, err := t.summarize(ctx, step.Agent, chain[i+1].Agent, output); err == nil && handoff != output {
+				result.Handoff = handoff
+				previous = handoff
+			}
+		}
+		results = append(results, result)
 	}
 
 	return json.Marshal(map[string]any{
@@ -181,6 +241,7 @@ func (t *SubAgentTool) executeChain(ctx context.Context, chain []subagentChain)
 }
 
 // executeParallel runs multiple sub-agents concurrently with bounded concurrency.
+// Tasks still waiting for a slot when ctx is canceled are not started.
 func (t *SubAgentTool) executeParallel(ctx context.Context, tasks []subagentTask) (json.RawMessage, error) {
 	if len(tasks) > maxParallelTasks {
 		return json.Marshal(fmt.Sprintf("Too many parallel tasks (%d). Max is %d.", len(tasks), maxParallelTasks))
@@ -194,7 +255,12 @@ func (t *SubAgentTool) executeParallel(ctx context.Context, tasks []subagentTask
 		wg.Add(1)
 		go func(idx int, st subagentTask) {
 			defer wg.Done()
-			sem <- struct{}{}
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				results[idx] = subagentResult{Agent: st.Agent, Task: st.Task, Output: ctx.Err().Error(), IsError: true}
+				return
+			}
 			defer func() { <-sem }()
 
 			output, err := t.runAgent(ctx, st.Agent, st.Task)
@@ -221,22 +287,28 @@ func (t *SubAgentTool) executeParallel(ctx context.Context, tasks []subagentTask
 		}
 	}
 
-	return json.Marshal(map[string]any{
+	return partialResult(ctx, map[string]any{
 		"summary": fmt.Sprintf("%d/%d succeeded", successCount, len(results)),
 		"results": results,
 	})
 }
 
+// partialResult marshals v and, if ctx was canceled, returns its error too,
+// so callers see the cancellation alongside the results gathered so far.
+func partialResult(ctx context.Context, v any) (json.RawMessage, error) {
+	out, err := json.Marshal(v)
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return out, ctxErr
+	}
+	return out, err
+}
+
 // runAgent executes an isolated agent loop for the given agent config and task.
 // Returns the final assistant output text.
 func (t *SubAgentTool) runAgent(ctx context.Context, agentName, task string) (string, error) {
 	cfg, ok := t.agents[agentName]
 	if !ok {
-		available := make([]string, 0, len(t.agents))
-		for name := range t.agents {
-			available = append(available, name)
-		}
-		return "", fmt.Errorf("unknown agent %q, available: %s", agentName, strings.Join(available, ", "))
+		return "", fmt.Errorf("unknown agent %q, available: %s", agentName, strings.Join(t.agentNames(), ", "))
 	}
 
 	userMsg := UserMsg(task)
@@ -274,6 +346,10 @@ func (t *SubAgentTool) runAgent(ctx context.Context, agentName, task string) (st
 		}
 	}
 
+	// A canceled run may end without an error event; don't report it as done.
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
 	if lastErr != nil && lastAssistantContent == "" {
 		return "", lastErr
 	}
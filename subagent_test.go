@@ -0,0 +1,164 @@
+package agentcore_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/voocel/agentcore"
+	"github.com/voocel/agentcore/llm/llmtest"
+)
+
+func TestSubAgentToolDeterministicOrder(t *testing.T) {
+	configs := []agentcore.SubAgentConfig{
+		{Name: "worker", Description: "does the work"},
+		{Name: "scout", Description: "finds things"},
+		{Name: "reviewer", Description: "checks the work"},
+		{Name: "archivist", Description: "keeps notes"},
+	}
+	want := agentcore.NewSubAgentTool(configs...)
+	wantSchema, _ := json.Marshal(want.Schema())
+
+	for i := range 20 {
+		shuffled := slices.Clone(configs)
+		slices.Reverse(shuffled[:i%len(shuffled)+1])
+		got := agentcore.NewSubAgentTool(shuffled...)
+		if got.Description() != want.Description() {
+			t.Fatalf("description differs:\n%s\n%s", got.Description(), want.Description())
+		}
+		if gotSchema, _ := json.Marshal(got.Schema()); string(gotSchema) != string(wantSchema) {
+			t.Fatalf("schema differs:\n%s\n%s", gotSchema, wantSchema)
+		}
+	}
+
+	desc := want.Description()
+	positions := make([]int, 0, len(configs))
+	for _, name := range []string{"archivist", "reviewer", "scout", "worker"} {
+		positions = append(positions, strings.Index(desc, name+" ("))
+	}
+	if !slices.IsSorted(positions) || positions[0] < 0 {
+		t.Fatalf("agents not listed alphabetically: %s", desc)
+	}
+
+	enum := want.Schema()["properties"].(map[string]any)["agent"].(map[string]any)["enum"]
+	var names []string
+	switch e := enum.(type) {
+	case []string:
+		names = e
+	case []any:
+		for _, v := range e {
+			names = append(names, v.(string))
+		}
+	}
+	if !slices.IsSorted(names) || len(names) != len(configs) {
+		t.Fatalf("agent enum = %v, want sorted names", names)
+	}
+}
+
+// cancelChain builds a three-step chain whose second step calls a tool that
+// runs stop, and returns the tool, the tool call arguments, and the third
+// step's model.
+func cancelChain(stop func()) (*agentcore.SubAgentTool, json.RawMessage, *llmtest.Model) {
+	stopTool := llmtest.NewTool("stop", func(context.Context, json.RawMessage) (json.RawMessage, error) {
+		stop()
+		return json.RawMessage(`"stopped"`), nil
+	})
+	third := llmtest.NewModel(llmtest.Text("three"))
+	tool := agentcore.NewSubAgentTool(
+		agentcore.SubAgentConfig{Name: "first", Model: llmtest.NewModel(llmtest.Text("one"))},
+		agentcore.SubAgentConfig{Name: "second", Tools: []agentcore.Tool{stopTool}, Model: llmtest.NewModel(
+			llmtest.ToolCalls(agentcore.ToolCall{ID: "s1", Name: "stop", Args: json.RawMessage(`{}`)}),
+			llmtest.Text("two"),
+		)},
+		agentcore.SubAgentConfig{Name: "third", Model: third},
+	)
+	args := json.RawMessage(`{"chain":[
+		{"agent":"first","task":"start"},
+		{"agent":"second","task":"continue from {previous}"},
+		{"agent":"third","task":"finish {previous}"}]}`)
+	return tool, args, third
+}
+
+type chainResult struct {
+	Error   string `json:"error"`
+	Results []struct {
+		Agent   string `json:"agent"`
+		Output  string `json:"output"`
+		IsError bool   `json:"is_error"`
+	} `json:"results"`
+}
+
+func TestSubAgentChainStopsOnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	tool, args, third := cancelChain(cancel)
+
+	out, err := tool.Execute(ctx, args)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want context.Canceled", err)
+	}
+	if n := len(third.Calls()); n != 0 {
+		t.Fatalf("third step ran %d model calls after cancellation", n)
+	}
+
+	var res chainResult
+	if err := json.Unmarshal(out, &res); err != nil {
+		t.Fatalf("partial output %s: %v", out, err)
+	}
+	if !strings.Contains(res.Error, "step 2") {
+		t.Errorf("error = %q, want it to name step 2", res.Error)
+	}
+	if len(res.Results) != 2 {
+		t.Fatalf("results = %+v, want the two steps that started", res.Results)
+	}
+	if r := res.Results[0]; r.Agent != "first" || r.Output != "one" || r.IsError {
+		t.Errorf("step 1 = %+v, want first's output", r)
+	}
+	if r := res.Results[1]; r.Agent != "second" || !r.IsError {
+		t.Errorf("step 2 = %+v, want a canceled step", r)
+	}
+}
+
+func TestSubAgentPartialResultReachesEvents(t *testing.T) {
+	var parent *agentcore.Agent
+	tool, args, third := cancelChain(func() { parent.Abort() })
+	parent = agentcore.NewAgent(
+		agentcore.WithModel(llmtest.NewModel(
+			llmtest.ToolCalls(agentcore.ToolCall{ID: "c1", Name: "subagent", Args: args}),
+			llmtest.Text("unreachable"),
+		)),
+		agentcore.WithTools(tool),
+	)
+
+	var end *agentcore.Event
+	for _, ev := range runEvents(t, parent, "go") {
+		if ev.Type == agentcore.EventToolExecEnd && ev.Tool == "subagent" {
+			end = &ev
+		}
+	}
+	if end == nil {
+		t.Fatal("no tool_exec_end event for the subagent call")
+	}
+	if !end.IsError {
+		t.Fatalf("result = %s, want an error result", end.Result)
+	}
+	var content struct {
+		Error         string      `json:"error"`
+		PartialOutput chainResult `json:"partial_output"`
+	}
+	if err := json.Unmarshal(end.Result, &content); err != nil {
+		t.Fatalf("result %s: %v", end.Result, err)
+	}
+	if !strings.Contains(content.Error, context.Canceled.Error()) {
+		t.Errorf("error = %q, want the context error", content.Error)
+	}
+	if got := content.PartialOutput.Results; len(got) == 0 || got[0].Output != "one" {
+		t.Errorf("partial_output = %+v, want the completed first step", content.PartialOutput)
+	}
+	if n := len(third.Calls()); n != 0 {
+		t.Fatalf("third step ran %d model calls after Abort", n)
+	}
+}
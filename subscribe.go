@@ -0,0 +1,114 @@
+package agentcore
+
+import (
+	"strings"
+	"sync"
+)
+
+// OverflowPolicy controls what an async subscriber does when its queue is full.
+type OverflowPolicy string
+
+const (
+	// OverflowDrop discards events that don't fit in the queue. The agent never waits.
+	OverflowDrop OverflowPolicy = "drop"
+	// OverflowBlock makes the agent wait until the subscriber has room (backpressure).
+	OverflowBlock OverflowPolicy = "block"
+)
+
+// SubscribeOption configures a listener registered with Agent.Subscribe.
+type SubscribeOption func(*subscription)
+
+type subscription struct {
+	patterns []string
+	async    bool
+	buffer   int
+	policy   OverflowPolicy
+}
+
+// WithEventFilter delivers only events whose type matches one of patterns.
+// A trailing "*" matches by prefix ("tool_exec_*", "message_*"); "*" alone
+// matches everything.
+func WithEventFilter(patterns ...string) SubscribeOption {
+	return func(s *subscription) { s.patterns = append(s.patterns, patterns...) }
+}
+
+// WithAsyncDelivery delivers events on a dedicated goroutine through a queue
+// of the given size, so a slow listener does not stall the agent unless
+// policy is OverflowBlock.
+//
+// Ordering: each subscriber sees events in emission order in both sync and
+// async mode; with OverflowDrop some events may be missing. No ordering is
+// guaranteed across different subscribers in async mode.
+func WithAsyncDelivery(buffer int, policy OverflowPolicy) SubscribeOption {
+	return func(s *subscription) {
+		s.async = true
+		s.buffer = buffer
+		s.policy = policy
+	}
+}
+
+func (s *subscription) matches(t EventType) bool {
+	return len(s.patterns) == 0 || MatchEventType(t, s.patterns...)
+}
+
+// MatchEventType reports whether t matches any of patterns, using the same
+// rules as WithEventFilter.
+func MatchEventType(t EventType, patterns ...string) bool {
+	for _, p := range patterns {
+		if p == "*" || p == string(t) {
+			return true
+		}
+		if prefix, ok := strings.CutSuffix(p, "*"); ok && strings.HasPrefix(string(t), prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// listener wraps fn with filtering and, for async subscriptions, a queue
+// drained by its own goroutine. stop releases the goroutine.
+func (s *subscription) listener(fn func(Event)) (deliver func(Event), stop func()) {
+	if !s.async {
+		return func(ev Event) {
+			if s.matches(ev.Type) {
+				fn(ev)
+			}
+		}, func() {}
+	}
+
+	buffer := s.buffer
+	if buffer <= 0 {
+		buffer = 64
+	}
+	queue := make(chan Event, buffer)
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case ev := <-queue:
+				fn(ev)
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	deliver = func(ev Event) {
+		if !s.matches(ev.Type) {
+			return
+		}
+		if s.policy == OverflowBlock {
+			select {
+			case queue <- ev:
+			case <-done:
+			}
+			return
+		}
+		select {
+		case queue <- ev:
+		default:
+		}
+	}
+	var once sync.Once
+	return deliver, func() { once.Do(func() { close(done) }) }
+}
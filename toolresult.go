@@ -0,0 +1,45 @@
+package agentcore
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"unicode/utf8"
+)
+
+// ToolResultProcessor rewrites a successful tool result before it enters the
+// conversation, e.g. to truncate or summarize large outputs. The tool_exec_end
+// event still carries the full result. The call identifies the tool so a
+// processor can be selective.
+type ToolResultProcessor func(ctx context.Context, call ToolCall, result json.RawMessage) json.RawMessage
+
+// TruncateToolResults returns a processor that cuts results larger than
+// maxBytes to their first maxBytes bytes, followed by a
+// "[...truncated N bytes...]" marker. The truncated result is a JSON string.
+// The cut never splits a UTF-8 character. maxBytes <= 0 disables truncation.
+//
+// Usage:
+//
+//	agentcore.WithToolResultProcessor(agentcore.TruncateToolResults(16 * 1024))
+func TruncateToolResults(maxBytes int) ToolResultProcessor {
+	return func(_ context.Context, _ ToolCall, result json.RawMessage) json.RawMessage {
+		if maxBytes <= 0 || len(result) <= maxBytes {
+			return result
+		}
+		// Truncate the text of string results rather than their JSON encoding.
+		text := string(result)
+		var s string
+		if json.Unmarshal(result, &s) == nil {
+			text = s
+		}
+		if len(text) <= maxBytes {
+			return result
+		}
+		cut := maxBytes
+		for cut > 0 && !utf8.RuneStart(text[cut]) {
+			cut--
+		}
+		out, _ := json.Marshal(fmt.Sprintf("%s\n[...truncated %d bytes...]", text[:cut], len(text)-cut))
+		return out
+	}
+}
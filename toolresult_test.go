@@ -0,0 +1,52 @@
+package agentcore
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestTruncateToolResults(t *testing.T) {
+	quote := func(s string) json.RawMessage {
+		b, _ := json.Marshal(s)
+		return b
+	}
+	tests := []struct {
+		name     string
+		maxBytes int
+		result   json.RawMessage
+		want     string // decoded text; "" means the result is returned unchanged
+	}{
+		{"under limit", 10, quote("short"), ""},
+		{"exact limit", 5, quote("abcde"), ""},
+		{"ascii cut", 3, quote("abcdef"), "abc\n[...truncated 3 bytes...]"},
+		{"no split inside rune", 4, quote("ab世界"), "ab\n[...truncated 6 bytes...]"},
+		{"cut on rune boundary", 5, quote("ab世界"), "ab世\n[...truncated 3 bytes...]"},
+		{"raw json object", 8, json.RawMessage(`{"k":"0123456789"}`), `{"k":"01` + "\n[...truncated 10 bytes...]"},
+		{"zero disables", 0, quote(strings.Repeat("x", 100)), ""},
+		{"negative disables", -1, quote(strings.Repeat("x", 100)), ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := TruncateToolResults(tt.maxBytes)(context.Background(), ToolCall{}, tt.result)
+			if tt.want == "" {
+				if string(out) != string(tt.result) {
+					t.Fatalf("result changed: %s", out)
+				}
+				return
+			}
+			var got string
+			if err := json.Unmarshal(out, &got); err != nil {
+				t.Fatalf("output is not a JSON string: %s", out)
+			}
+			if !utf8.ValidString(got) {
+				t.Fatalf("output is not valid UTF-8: %q", got)
+			}
+			if got != tt.want {
+				t.Fatalf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
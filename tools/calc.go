@@ -0,0 +1,374 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/voocel/agentcore/schema"
+)
+
+// CalculatorTool evaluates arithmetic expressions.
+// Supports + - * / % ^, parentheses, unary minus, the constants pi and e,
+// and the functions sqrt, abs, min, max, floor, ceil, round, ln, log.
+type CalculatorTool struct{}
+
+func NewCalculator() *CalculatorTool { return &CalculatorTool{} }
+
+func (t *CalculatorTool) Name() string  { return "calculator" }
+func (t *CalculatorTool) Label() string { return "Calculate" }
+func (t *CalculatorTool) Description() string {
+	return "Evaluate an arithmetic expression with standard precedence. " +
+		"Operators: + - * / % ^ and parentheses. Functions: sqrt, abs, min, max, floor, ceil, round, ln, log. Constants: pi, e."
+}
+func (t *CalculatorTool) Schema() map[string]any {
+	return schema.Object(
+		schema.Property("expression", schema.String("Expression to evaluate, e.g. \"15 + 25 * 2\"")).Required(),
+	)
+}
+
+type calcArgs struct {
+	Expression string `json:"expression"`
+}
+
+func (t *CalculatorTool) Execute(_ context.Context, args json.RawMessage) (json.RawMessage, error) {
+	var a calcArgs
+	if err := json.Unmarshal(args, &a); err != nil {
+		return nil, fmt.Errorf("invalid args: %w", err)
+	}
+	v, err := Evaluate(a.Expression)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(strconv.FormatFloat(v, 'g', -1, 64))
+}
+
+// Evaluation errors.
+var (
+	ErrDivisionByZero = errors.New("division by zero")
+	ErrOverflow       = errors.New("numeric overflow")
+)
+
+// Evaluate parses and evaluates an arithmetic expression.
+//
+// Grammar (lowest to highest precedence):
+//
+//	expr   = term { ("+" | "-") term }
+//	term   = unary { ("*" | "/" | "%") unary }
+//	unary  = ("-" | "+") unary | power
+//	power  = atom [ "^" unary ]          (right-associative)
+//	atom   = number | ident | ident "(" args ")" | "(" expr ")"
+func Evaluate(expr string) (float64, error) {
+	p := &calcParser{src: expr}
+	p.next()
+	v, err := p.expr()
+	if err != nil {
+		return 0, err
+	}
+	if p.tok.kind != tokEOF {
+		return 0, p.errorf("unexpected %q", p.tok.text)
+	}
+	return v, nil
+}
+
+type calcTokKind int
+
+const (
+	tokEOF calcTokKind = iota
+	tokNum
+	tokIdent
+	tokOp
+)
+
+type calcTok struct {
+	kind calcTokKind
+	text string
+	num  float64
+	pos  int
+}
+
+type calcParser struct {
+	src string
+	pos int
+	tok calcTok
+	err error
+}
+
+func (p *calcParser) errorf(format string, args ...any) error {
+	return fmt.Errorf("expression error at position %d: %s", p.tok.pos+1, fmt.Sprintf(format, args...))
+}
+
+// next advances to the next token. Lexing errors are stored and surfaced by the parser.
+func (p *calcParser) next() {
+	for p.pos < len(p.src) && unicode.IsSpace(rune(p.src[p.pos])) {
+		p.pos++
+	}
+	start := p.pos
+	if p.pos >= len(p.src) {
+		p.tok = calcTok{kind: tokEOF, pos: start}
+		return
+	}
+
+	c := p.src[p.pos]
+	switch {
+	case c >= '0' && c <= '9' || c == '.':
+		for p.pos < len(p.src) && (isDigitOrDot(p.src[p.pos]) || isExponent(p.src, p.pos)) {
+			if p.src[p.pos] == 'e' || p.src[p.pos] == 'E' {
+				p.pos++ // consume exponent marker and optional sign
+				if p.pos < len(p.src) && (p.src[p.pos] == '+' || p.src[p.pos] == '-') {
+					p.pos++
+				}
+				continue
+			}
+			p.pos++
+		}
+		text := p.src[start:p.pos]
+		n, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			p.err = fmt.Errorf("expression error at position %d: invalid number %q", start+1, text)
+		}
+		p.tok = calcTok{kind: tokNum, text: text, num: n, pos: start}
+	case unicode.IsLetter(rune(c)):
+		for p.pos < len(p.src) && (unicode.IsLetter(rune(p.src[p.pos])) || unicode.IsDigit(rune(p.src[p.pos]))) {
+			p.pos++
+		}
+		p.tok = calcTok{kind: tokIdent, text: strings.ToLower(p.src[start:p.pos]), pos: start}
+	case strings.IndexByte("+-*/%^(),", c) >= 0:
+		p.pos++
+		p.tok = calcTok{kind: tokOp, text: string(c), pos: start}
+	default:
+		p.pos++
+		p.err = fmt.Errorf("expression error at position %d: unexpected character %q", start+1, c)
+		p.tok = calcTok{kind: tokOp, text: string(c), pos: start}
+	}
+}
+
+func isDigitOrDot(c byte) bool { return c >= '0' && c <= '9' || c == '.' }
+
+// isExponent reports whether src[i] starts a scientific-notation exponent (e.g. 1e-3).
+func isExponent(src string, i int) bool {
+	if src[i] != 'e' && src[i] != 'E' {
+		return false
+	}
+	j := i + 1
+	if j < len(src) && (src[j] == '+' || src[j] == '-') {
+		j++
+	}
+	return j < len(src) && src[j] >= '0' && src[j] <= '9'
+}
+
+func (p *calcParser) isOp(op string) bool { return p.tok.kind == tokOp && p.tok.text == op }
+
+func (p *calcParser) expr() (float64, error) {
+	left, err := p.term()
+	if err != nil {
+		return 0, err
+	}
+	for p.isOp("+") || p.isOp("-") {
+		op := p.tok.text
+		p.next()
+		right, err := p.term()
+		if err != nil {
+			return 0, err
+		}
+		if op == "+" {
+			left += right
+		} else {
+			left -= right
+		}
+		if err := checkFinite(left); err != nil {
+			return 0, err
+		}
+	}
+	return left, nil
+}
+
+func (p *calcParser) term() (float64, error) {
+	left, err := p.unary()
+	if err != nil {
+		return 0, err
+	}
+	for p.isOp("*") || p.isOp("/") || p.isOp("%") {
+		op := p.tok.text
+		p.next()
+		right, err := p.unary()
+		if err != nil {
+			return 0, err
+		}
+		switch op {
+		case "*":
+			left *= right
+		case "/":
+			if right == 0 {
+				return 0, ErrDivisionByZero
+			}
+			left /= right
+		case "%":
+			if right == 0 {
+				return 0, ErrDivisionByZero
+			}
+			left = math.Mod(left, right)
+		}
+		if err := checkFinite(left); err != nil {
+			return 0, err
+		}
+	}
+	return left, nil
+}
+
+func (p *calcParser) unary() (float64, error) {
+	if p.isOp("-") || p.isOp("+") {
+		neg := p.tok.text == "-"
+		p.next()
+		v, err := p.unary()
+		if err != nil {
+			return 0, err
+		}
+		if neg {
+			v = -v
+		}
+		return v, nil
+	}
+	return p.power()
+}
+
+func (p *calcParser) power() (float64, error) {
+	base, err := p.atom()
+	if err != nil {
+		return 0, err
+	}
+	if !p.isOp("^") {
+		return base, nil
+	}
+	p.next()
+	exp, err := p.unary() // right-associative: 2^3^2 = 2^(3^2)
+	if err != nil {
+		return 0, err
+	}
+	v := math.Pow(base, exp)
+	if math.IsNaN(v) {
+		return 0, fmt.Errorf("invalid power: %g^%g", base, exp)
+	}
+	return v, checkFinite(v)
+}
+
+func (p *calcParser) atom() (float64, error) {
+	if p.err != nil {
+		return 0, p.err
+	}
+	switch p.tok.kind {
+	case tokNum:
+		v := p.tok.num
+		p.next()
+		return v, nil
+
+	case tokIdent:
+		name := p.tok.text
+		p.next()
+		if !p.isOp("(") {
+			switch name {
+			case "pi":
+				return math.Pi, nil
+			case "e":
+				return math.E, nil
+			}
+			return 0, fmt.Errorf("unknown identifier %q", name)
+		}
+		p.next()
+		var args []float64
+		if !p.isOp(")") {
+			for {
+				v, err := p.expr()
+				if err != nil {
+					return 0, err
+				}
+				args = append(args, v)
+				if !p.isOp(",") {
+					break
+				}
+				p.next()
+			}
+		}
+		if !p.isOp(")") {
+			return 0, p.errorf("expected ')' after arguments to %s", name)
+		}
+		p.next()
+		return callFunc(name, args)
+
+	case tokOp:
+		if p.isOp("(") {
+			p.next()
+			v, err := p.expr()
+			if err != nil {
+				return 0, err
+			}
+			if !p.isOp(")") {
+				return 0, p.errorf("expected ')'")
+			}
+			p.next()
+			return v, nil
+		}
+		return 0, p.errorf("unexpected %q", p.tok.text)
+	}
+	return 0, p.errorf("unexpected end of expression")
+}
+
+// callFunc applies a built-in function.
+func callFunc(name string, args []float64) (float64, error) {
+	unary := func(fn func(float64) float64) (float64, error) {
+		if len(args) != 1 {
+			return 0, fmt.Errorf("%s expects 1 argument, got %d", name, len(args))
+		}
+		return fn(args[0]), nil
+	}
+
+	switch name {
+	case "sqrt":
+		if len(args) == 1 && args[0] < 0 {
+			return 0, fmt.Errorf("sqrt of negative number %g", args[0])
+		}
+		return unary(math.Sqrt)
+	case "abs":
+		return unary(math.Abs)
+	case "floor":
+		return unary(math.Floor)
+	case "ceil":
+		return unary(math.Ceil)
+	case "round":
+		return unary(math.Round)
+	case "ln", "log":
+		if len(args) == 1 && args[0] <= 0 {
+			return 0, fmt.Errorf("%s of non-positive number %g", name, args[0])
+		}
+		if name == "ln" {
+			return unary(math.Log)
+		}
+		return unary(math.Log10)
+	case "min", "max":
+		if len(args) == 0 {
+			return 0, fmt.Errorf("%s expects at least 1 argument", name)
+		}
+		v := args[0]
+		for _, a := range args[1:] {
+			if name == "min" {
+				v = math.Min(v, a)
+			} else {
+				v = math.Max(v, a)
+			}
+		}
+		return v, nil
+	}
+	return 0, fmt.Errorf("unknown function %q", name)
+}
+
+func checkFinite(v float64) error {
+	if math.IsInf(v, 0) {
+		return ErrOverflow
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestEvaluate(t *testing.T) {
+	tests := []struct {
+		expr string
+		want float64
+	}{
+		// precedence and associativity
+		{"15 + 25 * 2", 65},
+		{"(15 + 25) * 2", 80},
+		{"10 - 4 - 3", 3},
+		{"24 / 4 / 2", 3},
+		{"7 % 4 * 2", 6},
+		{"2 ^ 3 ^ 2", 512},
+		{"2 * 3 ^ 2", 18},
+		// unary minus
+		{"-3", -3},
+		{"--3", 3},
+		{"-2 ^ 2", -4},
+		{"2 ^ -1", 0.5},
+		{"4 * -2", -8},
+		{"-(1 + 2)", -3},
+		{"+5", 5},
+		// numbers, constants, functions
+		{"1.5e2", 150},
+		{"2E-1", 0.2},
+		{"pi", math.Pi},
+		{"sqrt(16) + abs(-2)", 6},
+		{"max(1, 7, 3) - min(4, 2)", 5},
+		{"round(2.5) + floor(1.9) + ceil(1.1)", 6},
+		{"log(1000)", 3},
+		{"ln(e)", 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			got, err := Evaluate(tt.expr)
+			if err != nil {
+				t.Fatalf("Evaluate(%q): %v", tt.expr, err)
+			}
+			if math.Abs(got-tt.want) > 1e-9 {
+				t.Fatalf("Evaluate(%q) = %g, want %g", tt.expr, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEvaluateErrors(t *testing.T) {
+	tests := []struct {
+		expr string
+		want error // nil: any error
+	}{
+		{"1 / 0", ErrDivisionByZero},
+		{"5 % (2 - 2)", ErrDivisionByZero},
+		{"10 ^ 400", ErrOverflow},
+		{"", nil},
+		{"1 +", nil},
+		{"(1 + 2", nil},
+		{"1 + 2)", nil},
+		{"2 3", nil},
+		{"1 $ 2", nil},
+		{"1..2", nil},
+		{"foo", nil},
+		{"foo(1)", nil},
+		{"sqrt(-1)", nil},
+		{"sqrt(1, 2)", nil},
+		{"min()", nil},
+		{"(-8) ^ 0.5", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			v, err := Evaluate(tt.expr)
+			if err == nil {
+				t.Fatalf("Evaluate(%q) = %g, want error", tt.expr, v)
+			}
+			if tt.want != nil && !errors.Is(err, tt.want) {
+				t.Fatalf("Evaluate(%q) error = %v, want %v", tt.expr, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestCalculatorToolExecute(t *testing.T) {
+	out, err := NewCalculator().Execute(context.Background(), json.RawMessage(`{"expression":"1/4"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(out) != `"0.25"` {
+		t.Fatalf("got %s, want \"0.25\"", out)
+	}
+}
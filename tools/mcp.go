@@ -0,0 +1,579 @@
+package tools
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptrace"
+	"net/url"
+	"os/exec"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/voocel/agentcore"
+)
+
+const mcpProtocolVersion = "2024-11-05"
+
+var (
+	// errMCPNotSent marks a request that never reached the server, e.g. on a
+	// connection that had already dropped. The client reconnects once and
+	// retries it.
+	errMCPNotSent = errors.New("mcp: request not sent")
+
+	// errMCPDisconnected marks a request whose connection dropped after it was
+	// sent. It is not retried: the server may already have acted on it.
+	errMCPDisconnected = errors.New("mcp: server disconnected")
+)
+
+// MCPClient connects to a Model Context Protocol server and exposes its tools
+// as agentcore.Tool instances. The connection is established lazily on first
+// use and re-established when it drops; a call is retried on the new
+// connection only if its request was never sent, so non-idempotent tool calls
+// never run twice.
+//
+// Usage:
+//
+//	client := tools.NewMCPStdioClient("npx", "-y", "@modelcontextprotocol/server-filesystem", ".")
+//	defer client.Close()
+//	mcpTools, err := client.Tools(ctx)
+//	agent := agentcore.NewAgent(agentcore.WithTools(mcpTools...))
+type MCPClient struct {
+	newTransport func() mcpTransport
+
+	mu     sync.Mutex
+	conn   *mcpConn
+	nextID atomic.Int64
+	closed bool
+}
+
+// NewMCPClient creates a client for an MCP server using the HTTP+SSE transport.
+// serverURL is the SSE endpoint (e.g. http://localhost:3000/sse).
+func NewMCPClient(serverURL string) *MCPClient {
+	return &MCPClient{newTransport: func() mcpTransport {
+		return &mcpSSETransport{serverURL: serverURL, client: http.DefaultClient}
+	}}
+}
+
+// NewMCPStdioClient creates a client that launches the MCP server as a
+// subprocess and talks JSON-RPC over its stdin/stdout.
+func NewMCPStdioClient(command string, args ...string) *MCPClient {
+	return &MCPClient{newTransport: func() mcpTransport {
+		return &mcpStdioTransport{command: command, args: args}
+	}}
+}
+
+// mcpToolInfo is a tool descriptor from tools/list.
+type mcpToolInfo struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description"`
+	InputSchema map[string]any `json:"inputSchema"`
+}
+
+// Tools lists the server's tools and wraps each as an agentcore.Tool.
+func (c *MCPClient) Tools(ctx context.Context) ([]agentcore.Tool, error) {
+	var (
+		out    []agentcore.Tool
+		cursor string
+	)
+	for {
+		params := map[string]any{}
+		if cursor != "" {
+			params["cursor"] = cursor
+		}
+		var res struct {
+			Tools      []mcpToolInfo `json:"tools"`
+			NextCursor string        `json:"nextCursor"`
+		}
+		if err := c.call(ctx, "tools/list", params, &res); err != nil {
+			return nil, err
+		}
+		for _, info := range res.Tools {
+			out = append(out, &mcpTool{client: c, info: info})
+		}
+		if res.NextCursor == "" {
+			return out, nil
+		}
+		cursor = res.NextCursor
+	}
+}
+
+// Close shuts down the connection (and subprocess, for stdio).
+func (c *MCPClient) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.closed = true
+	if c.conn == nil {
+		return nil
+	}
+	err := c.conn.t.close()
+	c.conn = nil
+	return err
+}
+
+// call performs a JSON-RPC request, reconnecting and retrying once if the
+// request could not be sent.
+func (c *MCPClient) call(ctx context.Context, method string, params, result any) error {
+	for attempt := 0; ; attempt++ {
+		conn, err := c.connect(ctx)
+		if err != nil {
+			return err
+		}
+		err = conn.request(ctx, c.nextID.Add(1), method, params, result)
+		if errors.Is(err, errMCPNotSent) && attempt == 0 {
+			c.drop(conn)
+			continue
+		}
+		return err
+	}
+}
+
+// connect returns the live connection, establishing and initializing one if needed.
+func (c *MCPClient) connect(ctx context.Context) (*mcpConn, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return nil, errors.New("mcp: client closed")
+	}
+	if c.conn != nil && !c.conn.isDone() {
+		return c.conn, nil
+	}
+
+	t := c.newTransport()
+	if err := t.start(ctx); err != nil {
+		return nil, fmt.Errorf("mcp: connect: %w", err)
+	}
+	conn := newMCPConn(t)
+
+	initParams := map[string]any{
+		"protocolVersion": mcpProtocolVersion,
+		"capabilities":    map[string]any{},
+		"clientInfo":      map[string]any{"name": "agentcore", "version": "1.0.0"},
+	}
+	if err := conn.request(ctx, c.nextID.Add(1), "initialize", initParams, nil); err != nil {
+		t.close()
+		return nil, fmt.Errorf("mcp: initialize: %w", err)
+	}
+	if err := conn.notify("notifications/initialized"); err != nil {
+		t.close()
+		return nil, fmt.Errorf("mcp: initialize: %w", err)
+	}
+
+	c.conn = conn
+	return conn, nil
+}
+
+// drop discards a dead connection so the next call reconnects.
+func (c *MCPClient) drop(conn *mcpConn) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.conn == conn {
+		conn.t.close()
+		c.conn = nil
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Tool wrapper
+// ---------------------------------------------------------------------------
+
+// mcpTool proxies Execute to tools/call on the MCP server.
+type mcpTool struct {
+	client *MCPClient
+	info   mcpToolInfo
+}
+
+func (t *mcpTool) Name() string        { return t.info.Name }
+func (t *mcpTool) Description() string { return t.info.Description }
+
+// Schema returns the server's inputSchema, normalized to an object schema.
+func (t *mcpTool) Schema() map[string]any {
+	s := make(map[string]any, len(t.info.InputSchema)+2)
+	for k, v := range t.info.InputSchema {
+		s[k] = v
+	}
+	if _, ok := s["type"]; !ok {
+		s["type"] = "object"
+	}
+	if _, ok := s["properties"]; !ok {
+		s["properties"] = map[string]any{}
+	}
+	return s
+}
+
+type mcpContent struct {
+	Type string `json:"type"`
+	Text string `json:"text,omitempty"`
+}
+
+func (t *mcpTool) Execute(ctx context.Context, args json.RawMessage) (json.RawMessage, error) {
+	if len(args) == 0 {
+		args = json.RawMessage("{}")
+	}
+	var res struct {
+		Content []json.RawMessage `json:"content"`
+		IsError bool              `json:"isError"`
+	}
+	params := map[string]any{"name": t.info.Name, "arguments": args}
+	if err := t.client.call(ctx, "tools/call", params, &res); err != nil {
+		return nil, err
+	}
+
+	var parts []string
+	for _, raw := range res.Content {
+		var c mcpContent
+		if json.Unmarshal(raw, &c) == nil && c.Type == "text" {
+			parts = append(parts, c.Text)
+		} else {
+			parts = append(parts, string(raw)) // images/resources: pass through as JSON
+		}
+	}
+	text := strings.Join(parts, "\n")
+
+	if res.IsError {
+		if text == "" {
+			text = "tool reported an error"
+		}
+		return nil, fmt.Errorf("mcp tool %q: %s", t.info.Name, text)
+	}
+	return json.Marshal(text)
+}
+
+// ---------------------------------------------------------------------------
+// JSON-RPC connection
+// ---------------------------------------------------------------------------
+
+type mcpRequest struct {
+	JSONRPC string `json:"jsonrpc"`
+	ID      *int64 `json:"id,omitempty"`
+	Method  string `json:"method"`
+	Params  any    `json:"params,omitempty"`
+}
+
+type mcpResponse struct {
+	ID     *int64          `json:"id"`
+	Result json.RawMessage `json:"result"`
+	Error  *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// mcpConn multiplexes JSON-RPC requests over a transport.
+type mcpConn struct {
+	t       mcpTransport
+	mu      sync.Mutex
+	pending map[int64]chan mcpResponse
+	done    chan struct{}
+}
+
+func newMCPConn(t mcpTransport) *mcpConn {
+	c := &mcpConn{t: t, pending: make(map[int64]chan mcpResponse), done: make(chan struct{})}
+	go c.readLoop()
+	return c
+}
+
+// readLoop dispatches responses to waiting requests until the transport closes.
+func (c *mcpConn) readLoop() {
+	defer close(c.done)
+	for msg := range c.t.messages() {
+		var resp mcpResponse
+		if json.Unmarshal(msg, &resp) != nil || resp.ID == nil {
+			continue // server notifications/requests are ignored
+		}
+		c.mu.Lock()
+		ch, ok := c.pending[*resp.ID]
+		delete(c.pending, *resp.ID)
+		c.mu.Unlock()
+		if ok {
+			ch <- resp
+		}
+	}
+}
+
+func (c *mcpConn) isDone() bool {
+	select {
+	case <-c.done:
+		return true
+	default:
+		return false
+	}
+}
+
+func (c *mcpConn) request(ctx context.Context, id int64, method string, params, result any) error {
+	ch := make(chan mcpResponse, 1)
+	c.mu.Lock()
+	c.pending[id] = ch
+	c.mu.Unlock()
+	defer func() {
+		c.mu.Lock()
+		delete(c.pending, id)
+		c.mu.Unlock()
+	}()
+
+	data, err := json.Marshal(mcpRequest{JSONRPC: "2.0", ID: &id, Method: method, Params: params})
+	if err != nil {
+		return err
+	}
+	if c.isDone() {
+		return errMCPNotSent
+	}
+	if err := c.t.send(ctx, data); err != nil {
+		if errors.Is(err, errMCPNotSent) {
+			return err
+		}
+		return fmt.Errorf("%w: %v", errMCPDisconnected, err)
+	}
+
+	select {
+	case resp := <-ch:
+		if resp.Error != nil {
+			return fmt.Errorf("mcp: %s: %s (code %d)", method, resp.Error.Message, resp.Error.Code)
+		}
+		if result != nil && len(resp.Result) > 0 {
+			if err := json.Unmarshal(resp.Result, result); err != nil {
+				return fmt.Errorf("mcp: %s: decode result: %w", method, err)
+			}
+		}
+		return nil
+	case <-c.done:
+		return errMCPDisconnected
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (c *mcpConn) notify(method string) error {
+	data, err := json.Marshal(mcpRequest{JSONRPC: "2.0", Method: method})
+	if err != nil {
+		return err
+	}
+	return c.t.send(context.Background(), data)
+}
+
+// ---------------------------------------------------------------------------
+// Transports
+// ---------------------------------------------------------------------------
+
+// mcpTransport carries raw JSON-RPC messages to and from a server.
+// send wraps errMCPNotSent when it knows msg was not written.
+type mcpTransport interface {
+	start(ctx context.Context) error
+	send(ctx context.Context, msg []byte) error
+	messages() <-chan []byte // closed when the connection ends
+	close() error
+}
+
+// mcpStdioTransport runs the server as a subprocess with newline-delimited JSON.
+type mcpStdioTransport struct {
+	command string
+	args    []string
+
+	cmd   *exec.Cmd
+	stdin io.WriteCloser
+	in    chan []byte
+	wmu   sync.Mutex
+}
+
+func (t *mcpStdioTransport) start(_ context.Context) error {
+	t.cmd = exec.Command(t.command, t.args...)
+	stdin, err := t.cmd.StdinPipe()
+	if err != nil {
+		return err
+	}
+	stdout, err := t.cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := t.cmd.Start(); err != nil {
+		return fmt.Errorf("start %s: %w", t.command, err)
+	}
+	t.stdin = stdin
+	t.in = make(chan []byte, 16)
+
+	go func() {
+		defer close(t.in)
+		scanner := bufio.NewScanner(stdout)
+		scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+		for scanner.Scan() {
+			line := bytes.TrimSpace(scanner.Bytes())
+			if len(line) == 0 {
+				continue
+			}
+			t.in <- append([]byte(nil), line...)
+		}
+	}()
+	return nil
+}
+
+func (t *mcpStdioTransport) send(_ context.Context, msg []byte) error {
+	t.wmu.Lock()
+	defer t.wmu.Unlock()
+	// A failed write leaves at most a partial line, which the server cannot
+	// parse as a request.
+	if _, err := t.stdin.Write(append(msg, '\n')); err != nil {
+		return fmt.Errorf("%w: %v", errMCPNotSent, err)
+	}
+	return nil
+}
+
+func (t *mcpStdioTransport) messages() <-chan []byte { return t.in }
+
+func (t *mcpStdioTransport) close() error {
+	if t.cmd == nil || t.cmd.Process == nil {
+		return nil
+	}
+	t.stdin.Close()
+	_ = t.cmd.Process.Kill()
+	_ = t.cmd.Wait()
+	return nil
+}
+
+// mcpSSETransport implements the HTTP+SSE transport: server-to-client
+// messages arrive on a long-lived SSE stream, client-to-server messages are
+// POSTed to the endpoint announced in the stream's first "endpoint" event.
+type mcpSSETransport struct {
+	serverURL string
+	client    *http.Client
+
+	endpoint string
+	in       chan []byte
+	cancel   context.CancelFunc
+}
+
+func (t *mcpSSETransport) start(ctx context.Context) error {
+	// The stream outlives the call that opened it.
+	streamCtx, cancel := context.WithCancel(context.Background())
+	req, err := http.NewRequestWithContext(streamCtx, http.MethodGet, t.serverURL, nil)
+	if err != nil {
+		cancel()
+		return err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	resp, err := t.client.Do(req)
+	if err != nil {
+		cancel()
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		cancel()
+		return fmt.Errorf("sse status %d", resp.StatusCode)
+	}
+
+	t.cancel = cancel
+	t.in = make(chan []byte, 16)
+	endpoint := make(chan string, 1)
+	streamDone := make(chan struct{})
+
+	go func() {
+		defer close(t.in)
+		defer close(streamDone)
+		defer resp.Body.Close()
+		readSSE(resp.Body, func(event, data string) {
+			switch event {
+			case "endpoint":
+				select {
+				case endpoint <- data:
+				default:
+				}
+			case "", "message":
+				t.in <- []byte(data)
+			}
+		})
+	}()
+
+	var ep string
+	select {
+	case ep = <-endpoint:
+	case <-streamDone:
+		select {
+		case ep = <-endpoint: // announced just before the stream closed
+		default:
+			t.close()
+			return errors.New("sse stream closed before endpoint event")
+		}
+	case <-ctx.Done():
+		t.close()
+		return ctx.Err()
+	}
+
+	base, err := url.Parse(t.serverURL)
+	if err != nil {
+		t.close()
+		return err
+	}
+	ref, err := url.Parse(ep)
+	if err != nil {
+		t.close()
+		return fmt.Errorf("invalid endpoint %q: %w", ep, err)
+	}
+	t.endpoint = base.ResolveReference(ref).String()
+	return nil
+}
+
+func (t *mcpSSETransport) send(ctx context.Context, msg []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.endpoint, bytes.NewReader(msg))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	var written atomic.Bool
+	req = req.WithContext(httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+		WroteRequest: func(info httptrace.WroteRequestInfo) { written.Store(info.Err == nil) },
+	}))
+	resp, err := t.client.Do(req)
+	if err != nil {
+		if !written.Load() {
+			return fmt.Errorf("%w: %v", errMCPNotSent, err)
+		}
+		return err
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("post status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func (t *mcpSSETransport) messages() <-chan []byte { return t.in }
+
+func (t *mcpSSETransport) close() error {
+	if t.cancel != nil {
+		t.cancel()
+	}
+	return nil
+}
+
+// readSSE parses a text/event-stream body, invoking fn for each complete event.
+func readSSE(r io.Reader, fn func(event, data string)) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	var (
+		event string
+		data  []string
+	)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			if len(data) > 0 {
+				fn(event, strings.Join(data, "\n"))
+			}
+			event, data = "", nil
+		case strings.HasPrefix(line, ":"):
+			// comment / keep-alive
+		case strings.HasPrefix(line, "event:"):
+			event = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		case strings.HasPrefix(line, "data:"):
+			data = append(data, strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		}
+	}
+}
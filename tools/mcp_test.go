@@ -0,0 +1,123 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeMCPTransport answers initialize itself and hands every other request
+// to handle, which may reply, fail the send, or drop the connection.
+type fakeMCPTransport struct {
+	handle func(t *fakeMCPTransport, method string, id int64) error
+
+	in        chan []byte
+	closeOnce sync.Once
+}
+
+func (t *fakeMCPTransport) start(context.Context) error {
+	t.in = make(chan []byte, 16)
+	return nil
+}
+
+func (t *fakeMCPTransport) send(_ context.Context, msg []byte) error {
+	var req mcpRequest
+	if err := json.Unmarshal(msg, &req); err != nil {
+		return err
+	}
+	if req.ID == nil {
+		return nil // notification
+	}
+	if req.Method == "initialize" {
+		t.reply(*req.ID, `{}`)
+		return nil
+	}
+	return t.handle(t, req.Method, *req.ID)
+}
+
+func (t *fakeMCPTransport) reply(id int64, result string) {
+	t.in <- []byte(fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"result":%s}`, id, result))
+}
+
+func (t *fakeMCPTransport) messages() <-chan []byte { return t.in }
+
+func (t *fakeMCPTransport) close() error {
+	t.closeOnce.Do(func() { close(t.in) })
+	return nil
+}
+
+func TestMCPCallNotRetriedAfterSend(t *testing.T) {
+	var (
+		mu         sync.Mutex
+		transports int
+		calls      int
+	)
+	c := &MCPClient{newTransport: func() mcpTransport {
+		mu.Lock()
+		transports++
+		mu.Unlock()
+		return &fakeMCPTransport{handle: func(ft *fakeMCPTransport, method string, id int64) error {
+			mu.Lock()
+			calls++
+			mu.Unlock()
+			ft.close() // server dies after receiving the request
+			return nil
+		}}
+	}}
+	defer c.Close()
+
+	err := c.call(context.Background(), "tools/call", nil, nil)
+	if !errors.Is(err, errMCPDisconnected) {
+		t.Fatalf("err = %v, want errMCPDisconnected", err)
+	}
+	if calls != 1 || transports != 1 {
+		t.Fatalf("calls = %d, transports = %d; want the request sent once on one connection", calls, transports)
+	}
+}
+
+func TestMCPCallRetriedWhenNotSent(t *testing.T) {
+	var transports int
+	c := &MCPClient{newTransport: func() mcpTransport {
+		transports++
+		first := transports == 1
+		return &fakeMCPTransport{handle: func(ft *fakeMCPTransport, method string, id int64) error {
+			if first {
+				ft.close()
+				return fmt.Errorf("%w: broken pipe", errMCPNotSent)
+			}
+			ft.reply(id, `{"ok":true}`)
+			return nil
+		}}
+	}}
+	defer c.Close()
+
+	var res struct{ OK bool }
+	if err := c.call(context.Background(), "tools/call", nil, &res); err != nil {
+		t.Fatal(err)
+	}
+	if !res.OK || transports != 2 {
+		t.Fatalf("ok = %v, transports = %d; want a successful retry on a second connection", res.OK, transports)
+	}
+}
+
+func TestMCPSSEStartStreamClosedBeforeEndpoint(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.WriteHeader(http.StatusOK) // no endpoint event
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	tr := &mcpSSETransport{serverURL: srv.URL, client: srv.Client()}
+	err := tr.start(ctx)
+	if err == nil || ctx.Err() != nil {
+		t.Fatalf("start = %v (ctx %v), want an immediate error", err, ctx.Err())
+	}
+}
@@ -0,0 +1,274 @@
+package tools
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/voocel/agentcore/schema"
+)
+
+// ErrSQLWriteDenied is returned when a read-only SQLTool receives a statement
+// that could modify data.
+var ErrSQLWriteDenied = errors.New("only read-only statements (SELECT, WITH, EXPLAIN, SHOW, DESCRIBE) are allowed")
+
+// SQLTool runs SQL against a database/sql connection and returns rows as JSON.
+// Read-only by default: statements that are not reads or that mention a
+// data-modifying keyword anywhere are rejected, and queries run in a read-only
+// transaction. Without AllowWrites, a driver that cannot start one fails the
+// call rather than running the query unprotected. Values are bound through
+// params, never interpolated into the query text.
+type SQLTool struct {
+	DB          *sql.DB
+	AllowWrites bool          // permit INSERT/UPDATE/DELETE/DDL, default false
+	MaxRows     int           // rows returned per query, default 200
+	Timeout     time.Duration // per query, default 30s
+}
+
+func NewSQL(db *sql.DB) *SQLTool {
+	return &SQLTool{DB: db, MaxRows: 200, Timeout: 30 * time.Second}
+}
+
+func (t *SQLTool) Name() string  { return "sql_query" }
+func (t *SQLTool) Label() string { return "SQL Query" }
+func (t *SQLTool) Description() string {
+	mode := "Only read-only statements are allowed."
+	if t.AllowWrites {
+		mode = "Write statements are allowed."
+	}
+	return fmt.Sprintf(
+		"Run a single SQL statement and return the result rows as JSON (at most %d rows). %s "+
+			"Pass user-provided values through params using the driver's placeholder syntax instead of embedding them in the query.",
+		t.maxRows(), mode,
+	)
+}
+func (t *SQLTool) Schema() map[string]any {
+	return schema.Object(
+		schema.Property("query", schema.String("A single SQL statement")).Required(),
+		schema.Property("params", schema.Array("Positional values bound to the query placeholders", map[string]any{})),
+	)
+}
+
+type sqlArgs struct {
+	Query  string `json:"query"`
+	Params []any  `json:"params"`
+}
+
+type sqlResult struct {
+	Columns   []string         `json:"columns"`
+	Rows      []map[string]any `json:"rows"`
+	Truncated bool             `json:"truncated,omitempty"`
+}
+
+func (t *SQLTool) maxRows() int {
+	if t.MaxRows <= 0 {
+		return 200
+	}
+	return t.MaxRows
+}
+
+func (t *SQLTool) Execute(ctx context.Context, args json.RawMessage) (json.RawMessage, error) {
+	var a sqlArgs
+	if err := json.Unmarshal(args, &a); err != nil {
+		return nil, fmt.Errorf("invalid args: %w", err)
+	}
+	if t.DB == nil {
+		return nil, fmt.Errorf("no database configured")
+	}
+
+	// Conservative: a ';' inside a string literal is rejected too.
+	query := strings.TrimSpace(a.Query)
+	if strings.Contains(strings.TrimRight(query, "; \t\n"), ";") {
+		return nil, fmt.Errorf("multiple statements are not allowed")
+	}
+	readOnly := isReadOnlySQL(query)
+	if !readOnly && !t.AllowWrites {
+		return nil, ErrSQLWriteDenied
+	}
+
+	timeout := t.Timeout
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	if !readOnly {
+		res, err := t.DB.ExecContext(ctx, query, a.Params...)
+		if err != nil {
+			return nil, fmt.Errorf("exec: %w", err)
+		}
+		n, _ := res.RowsAffected()
+		return json.Marshal(map[string]int64{"rows_affected": n})
+	}
+
+	// Defense in depth: a read-only transaction also blocks writes hidden in
+	// functions. Only a tool that may write anyway falls back to a plain query.
+	var q interface {
+		QueryContext(context.Context, string, ...any) (*sql.Rows, error)
+	} = t.DB
+	tx, err := t.DB.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	switch {
+	case err == nil:
+		defer tx.Rollback()
+		q = tx
+	case !t.AllowWrites:
+		return nil, fmt.Errorf("cannot start a read-only transaction: %w", err)
+	}
+
+	rows, err := q.QueryContext(ctx, query, a.Params...)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+	defer rows.Close()
+
+	result, err := scanRows(rows, t.maxRows())
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(result)
+}
+
+// scanRows reads up to limit rows into column-keyed maps.
+func scanRows(rows *sql.Rows, limit int) (sqlResult, error) {
+	cols, err := rows.Columns()
+	if err != nil {
+		return sqlResult{}, fmt.Errorf("columns: %w", err)
+	}
+	result := sqlResult{Columns: cols, Rows: []map[string]any{}}
+
+	vals := make([]any, len(cols))
+	ptrs := make([]any, len(cols))
+	for i := range vals {
+		ptrs[i] = &vals[i]
+	}
+	for rows.Next() {
+		if len(result.Rows) >= limit {
+			result.Truncated = true
+			break
+		}
+		if err := rows.Scan(ptrs...); err != nil {
+			return sqlResult{}, fmt.Errorf("scan: %w", err)
+		}
+		row := make(map[string]any, len(cols))
+		for i, c := range cols {
+			if b, ok := vals[i].([]byte); ok {
+				row[c] = string(b) // text columns often arrive as []byte
+			} else {
+				row[c] = vals[i]
+			}
+		}
+		result.Rows = append(result.Rows, row)
+	}
+	if err := rows.Err(); err != nil {
+		return sqlResult{}, fmt.Errorf("rows: %w", err)
+	}
+	return result, nil
+}
+
+// sqlWriteKeywords can modify data or schema wherever they appear, e.g. in a
+// data-modifying CTE (WITH d AS (DELETE ...)), EXPLAIN ANALYZE DELETE, or
+// SELECT ... INTO.
+var sqlWriteKeywords = map[string]bool{
+	"INSERT": true, "UPDATE": true, "DELETE": true, "MERGE": true, "UPSERT": true,
+	"CREATE": true, "ALTER": true, "DROP": true, "TRUNCATE": true, "RENAME": true,
+	"GRANT": true, "REVOKE": true, "INTO": true, "COPY": true, "CALL": true,
+	"EXEC": true, "EXECUTE": true, "ATTACH": true, "DETACH": true, "VACUUM": true,
+	"REINDEX": true, "PRAGMA": true, "LOCK": true, "SET": true,
+}
+
+// isReadOnlySQL reports whether the statement starts with a read keyword and
+// contains no write keyword outside string literals, quoted identifiers, and
+// comments. Dialects disagree on backslash escapes in literals, so the query
+// must pass under both readings. Unterminated quotes or comments fail.
+func isReadOnlySQL(query string) bool {
+	for _, backslashEscapes := range []bool{false, true} {
+		words, ok := sqlKeywords(query, backslashEscapes)
+		if !ok || len(words) == 0 {
+			return false
+		}
+		switch words[0] {
+		case "SELECT", "WITH", "EXPLAIN", "SHOW", "DESCRIBE", "DESC", "VALUES":
+		default:
+			return false
+		}
+		for _, w := range words[1:] {
+			if sqlWriteKeywords[w] {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// sqlKeywords returns the upper-cased bare words of query, skipping string
+// literals, quoted identifiers, dollar-quoted strings, and comments. ok is
+// false when a quote or comment is left open.
+func sqlKeywords(query string, backslashEscapes bool) (words []string, ok bool) {
+	isWord := func(c byte) bool { return c == '_' || unicode.IsLetter(rune(c)) || unicode.IsDigit(rune(c)) }
+	for i := 0; i < len(query); {
+		c := query[i]
+		switch {
+		case c == '\'' || c == '"' || c == '`':
+			j := i + 1
+			for ; j < len(query) && query[j] != c; j++ {
+				if backslashEscapes && query[j] == '\\' {
+					j++
+				}
+			}
+			if j >= len(query) {
+				return nil, false
+			}
+			i = j + 1 // a doubled quote ('') just starts the next literal
+		case c == '[':
+			end := strings.IndexByte(query[i+1:], ']')
+			if end < 0 {
+				return nil, false
+			}
+			i += end + 2
+		case c == '$':
+			// Dollar quoting: $$...$$ or $tag$...$tag$. Placeholders like $1 pass.
+			j := i + 1
+			for j < len(query) && isWord(query[j]) {
+				j++
+			}
+			if j >= len(query) || query[j] != '$' || (j > i+1 && unicode.IsDigit(rune(query[i+1]))) {
+				i = j
+				continue
+			}
+			tag := query[i : j+1]
+			end := strings.Index(query[j+1:], tag)
+			if end < 0 {
+				return nil, false
+			}
+			i = j + 1 + end + len(tag)
+		case strings.HasPrefix(query[i:], "--"):
+			end := strings.IndexByte(query[i:], '\n')
+			if end < 0 {
+				return words, true
+			}
+			i += end + 1
+		case strings.HasPrefix(query[i:], "/*"):
+			end := strings.Index(query[i+2:], "*/")
+			if end < 0 {
+				return nil, false
+			}
+			i += end + 4
+		case c == '_' || unicode.IsLetter(rune(c)):
+			j := i + 1
+			for j < len(query) && (isWord(query[j]) || query[j] == '$') {
+				j++
+			}
+			words = append(words, strings.ToUpper(query[i:j]))
+			i = j
+		default:
+			i++
+		}
+	}
+	return words, true
+}
@@ -0,0 +1,83 @@
+package tools
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestIsReadOnlySQL(t *testing.T) {
+	tests := []struct {
+		query string
+		want  bool
+	}{
+		{"SELECT * FROM users", true},
+		{"  select id from t where name = 'delete me'", true},
+		{`SELECT "update", [insert], ` + "`drop`" + ` FROM t`, true},
+		{"-- list users\nSELECT 1", true},
+		{"/* comment */ WITH x AS (SELECT 1) SELECT * FROM x", true},
+		{"EXPLAIN SELECT * FROM t", true},
+		{"VALUES (1), (2)", true},
+		{"SHOW TABLES", true},
+		{"SELECT $1, $$delete$$", true},
+		{"SELECT replace(name, 'a', 'b') FROM t ORDER BY id DESC", true},
+
+		{"DELETE FROM users", false},
+		{"WITH d AS (DELETE FROM users RETURNING *) SELECT * FROM d", false},
+		{"WITH x AS (SELECT 1) DELETE FROM users", false},
+		{"EXPLAIN ANALYZE DELETE FROM users", false},
+		{"EXPLAIN ANALYZE UPDATE users SET admin = true", false},
+		{"VALUES (1) ; DROP TABLE users", false},
+		{"SELECT * INTO backup FROM users", false},
+		{"SELECT * FROM t /* unterminated", false},
+		{"SELECT 'unterminated", false},
+		{`SELECT 'a\' , (DELETE FROM t) --'`, false},
+		{"SELECT $x$ unterminated", false},
+		{"-- only a comment", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := isReadOnlySQL(tt.query); got != tt.want {
+			t.Errorf("isReadOnlySQL(%q) = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestSQLToolRejectsWithoutReadOnlyTransaction(t *testing.T) {
+	db := sql.OpenDB(noTxConnector{})
+	defer db.Close()
+
+	tool := NewSQL(db)
+	_, err := tool.Execute(context.Background(), json.RawMessage(`{"query":"SELECT 1"}`))
+	if err == nil || !strings.Contains(err.Error(), "read-only transaction") {
+		t.Fatalf("err = %v, want read-only transaction failure", err)
+	}
+
+	_, err = tool.Execute(context.Background(), json.RawMessage(`{"query":"WITH d AS (DELETE FROM t) SELECT 1"}`))
+	if !errors.Is(err, ErrSQLWriteDenied) {
+		t.Fatalf("err = %v, want ErrSQLWriteDenied", err)
+	}
+
+	tool.AllowWrites = true
+	_, err = tool.Execute(context.Background(), json.RawMessage(`{"query":"SELECT 1"}`))
+	if err == nil || !strings.Contains(err.Error(), "query:") {
+		t.Fatalf("err = %v, want the plain query to run", err)
+	}
+}
+
+// noTxConnector yields connections that support neither read-only
+// transactions nor queries, so reaching the driver is observable.
+type noTxConnector struct{}
+
+func (noTxConnector) Connect(context.Context) (driver.Conn, error) { return noTxConn{}, nil }
+func (noTxConnector) Driver() driver.Driver                        { return nil }
+
+type noTxConn struct{}
+
+func (noTxConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (noTxConn) Close() error                        { return nil }
+func (noTxConn) Begin() (driver.Tx, error)           { return nil, errors.New("not supported") }
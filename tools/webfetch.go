@@ -0,0 +1,346 @@
+package tools
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/voocel/agentcore/schema"
+)
+
+// ErrRobotsDisallowed is returned when robots.txt forbids fetching a URL.
+var ErrRobotsDisallowed = errors.New("fetching this URL is disallowed by the site's robots.txt")
+
+const (
+	defaultFetchUserAgent = "agentcore-webfetch/1.0"
+	maxFetchBodyBytes     = 5 * 1024 * 1024
+)
+
+// WebFetchTool downloads a web page and returns its readable text. HTML is
+// reduced to the main content (article/main when present) with scripts,
+// navigation, headers, and footers removed. robots.txt is honored unless
+// IgnoreRobots is set.
+type WebFetchTool struct {
+	Client       *http.Client  // default http.DefaultClient
+	Timeout      time.Duration // per fetch including robots.txt, default 30s
+	MaxBytes     int           // text returned to the model, default 50KB
+	UserAgent    string        // default "agentcore-webfetch/1.0"
+	IgnoreRobots bool
+
+	mu     sync.Mutex
+	robots map[string]string // host -> robots.txt body, "" when absent
+}
+
+func NewWebFetch() *WebFetchTool {
+	return &WebFetchTool{Timeout: 30 * time.Second, MaxBytes: defaultMaxBytes}
+}
+
+func (t *WebFetchTool) Name() string  { return "web_fetch" }
+func (t *WebFetchTool) Label() string { return "Fetch Web Page" }
+func (t *WebFetchTool) Description() string {
+	return fmt.Sprintf(
+		"Fetch an http(s) URL and return the page title and readable text (truncated to %s). HTML is converted to plain text.",
+		formatSize(t.maxBytes()),
+	)
+}
+func (t *WebFetchTool) Schema() map[string]any {
+	return schema.Object(
+		schema.Property("url", schema.String("Absolute http or https URL")).Required(),
+	)
+}
+
+type webFetchArgs struct {
+	URL string `json:"url"`
+}
+
+type webFetchResult struct {
+	URL       string `json:"url"`
+	Title     string `json:"title,omitempty"`
+	Content   string `json:"content"`
+	Truncated bool   `json:"truncated,omitempty"`
+}
+
+func (t *WebFetchTool) maxBytes() int {
+	if t.MaxBytes <= 0 {
+		return defaultMaxBytes
+	}
+	return t.MaxBytes
+}
+
+func (t *WebFetchTool) userAgent() string {
+	if t.UserAgent == "" {
+		return defaultFetchUserAgent
+	}
+	return t.UserAgent
+}
+
+func (t *WebFetchTool) client() *http.Client {
+	if t.Client == nil {
+		return http.DefaultClient
+	}
+	return t.Client
+}
+
+func (t *WebFetchTool) Execute(ctx context.Context, args json.RawMessage) (json.RawMessage, error) {
+	var a webFetchArgs
+	if err := json.Unmarshal(args, &a); err != nil {
+		return nil, fmt.Errorf("invalid args: %w", err)
+	}
+	u, err := url.Parse(strings.TrimSpace(a.URL))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("invalid url %q: must be an absolute http or https URL", a.URL)
+	}
+
+	timeout := t.Timeout
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	if !t.IgnoreRobots {
+		allowed, err := t.robotsAllowed(ctx, u)
+		if err != nil {
+			return nil, err
+		}
+		if !allowed {
+			return nil, ErrRobotsDisallowed
+		}
+	}
+
+	body, resp, err := t.get(ctx, u.String())
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetch %s: HTTP %d", u, resp.StatusCode)
+	}
+
+	result := webFetchResult{URL: resp.Request.URL.String()}
+	contentType := strings.ToLower(resp.Header.Get("Content-Type"))
+	switch {
+	case contentType == "" || strings.Contains(contentType, "html"):
+		result.Title, result.Content = htmlToText(body)
+	case strings.HasPrefix(contentType, "text/"), strings.Contains(contentType, "json"), strings.Contains(contentType, "xml"):
+		result.Content = body
+	default:
+		return nil, fmt.Errorf("fetch %s: unsupported content type %q", u, contentType)
+	}
+	result.Content, _, _, result.Truncated = truncateHead(result.Content, 0, t.maxBytes())
+	return json.Marshal(result)
+}
+
+// get fetches rawURL and reads at most maxFetchBodyBytes of the body.
+func (t *WebFetchTool) get(ctx context.Context, rawURL string) (string, *http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return "", nil, err
+	}
+	req.Header.Set("User-Agent", t.userAgent())
+	resp, err := t.client().Do(req)
+	if err != nil {
+		return "", nil, fmt.Errorf("fetch %s: %w", rawURL, err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxFetchBodyBytes))
+	if err != nil {
+		return "", nil, fmt.Errorf("fetch %s: %w", rawURL, err)
+	}
+	return string(data), resp, nil
+}
+
+// robotsAllowed fetches (and caches per host) robots.txt and checks u
+// against it. A missing robots.txt allows everything; a server error
+// disallows everything, as RFC 9309 prescribes.
+func (t *WebFetchTool) robotsAllowed(ctx context.Context, u *url.URL) (bool, error) {
+	host := u.Scheme + "://" + u.Host
+
+	t.mu.Lock()
+	rules, cached := t.robots[host]
+	t.mu.Unlock()
+
+	if !cached {
+		body, resp, err := t.get(ctx, host+"/robots.txt")
+		switch {
+		case err != nil:
+			return false, fmt.Errorf("robots.txt: %w", err)
+		case resp.StatusCode >= 500:
+			return false, nil
+		case resp.StatusCode == http.StatusOK:
+			rules = body
+		}
+		t.mu.Lock()
+		if t.robots == nil {
+			t.robots = make(map[string]string)
+		}
+		t.robots[host] = rules
+		t.mu.Unlock()
+	}
+
+	path := u.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	if u.RawQuery != "" {
+		path += "?" + u.RawQuery
+	}
+	return robotsAllows(rules, t.userAgent(), path), nil
+}
+
+// robotsAllows applies the group for userAgent (or "*") from a robots.txt
+// body to path. The longest matching rule wins; Allow wins ties.
+func robotsAllows(robots, userAgent, path string) bool {
+	token := strings.ToLower(userAgent)
+	if i := strings.IndexByte(token, '/'); i >= 0 {
+		token = token[:i]
+	}
+
+	type rule struct {
+		allow   bool
+		pattern string
+	}
+	var (
+		specific, wildcard []rule
+		agents             []string
+		inRules            bool // a rule line ends the current User-agent list
+	)
+	sc := bufio.NewScanner(strings.NewReader(robots))
+	for sc.Scan() {
+		line := sc.Text()
+		if i := strings.IndexByte(line, '#'); i >= 0 {
+			line = line[:i]
+		}
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+		switch key {
+		case "user-agent":
+			if inRules {
+				agents, inRules = nil, false
+			}
+			agents = append(agents, strings.ToLower(value))
+		case "allow", "disallow":
+			inRules = true
+			if value == "" {
+				continue // empty Disallow allows everything
+			}
+			r := rule{allow: key == "allow", pattern: value}
+			for _, ag := range agents {
+				switch {
+				case ag == "*":
+					wildcard = append(wildcard, r)
+				case strings.Contains(token, ag):
+					specific = append(specific, r)
+				}
+			}
+		}
+	}
+
+	rules := wildcard
+	if len(specific) > 0 {
+		rules = specific
+	}
+	best, allowed := -1, true
+	for _, r := range rules {
+		if !robotsMatch(r.pattern, path) {
+			continue
+		}
+		if n := len(r.pattern); n > best || (n == best && r.allow) {
+			best, allowed = n, r.allow
+		}
+	}
+	return allowed
+}
+
+// robotsMatch reports whether a robots.txt path pattern (with * and a
+// trailing $) matches path.
+func robotsMatch(pattern, path string) bool {
+	if !strings.ContainsAny(pattern, "*$") {
+		return strings.HasPrefix(path, pattern)
+	}
+	expr := "^" + strings.ReplaceAll(regexp.QuoteMeta(pattern), `\*`, ".*")
+	expr = strings.TrimSuffix(expr, `\$`)
+	if strings.HasSuffix(pattern, "$") {
+		expr += "$"
+	}
+	re, err := regexp.Compile(expr)
+	return err == nil && re.MatchString(path)
+}
+
+var (
+	tagRe     = regexp.MustCompile(`(?s)<[^>]*>`)
+	commentRe = regexp.MustCompile(`(?s)<!--.*?-->`)
+	titleRe   = regexp.MustCompile(`(?is)<title\b[^>]*>(.*?)</title\s*>`)
+	listRe    = regexp.MustCompile(`(?i)<li\b[^>]*>`)
+	blockRe   = regexp.MustCompile(`(?i)</?(p|div|section|article|main|h[1-6]|ul|ol|tr|table|blockquote|pre|br|hr|dd|dt|figcaption)\b[^>]*>`)
+
+	// boilerplateRes drop elements that are never main content.
+	boilerplateRes = func() []*regexp.Regexp {
+		var res []*regexp.Regexp
+		for _, tag := range []string{"head", "script", "style", "noscript", "template", "svg", "iframe", "nav", "header", "footer", "aside", "form"} {
+			res = append(res, regexp.MustCompile(`(?is)<`+tag+`\b[^>]*>.*?</`+tag+`\s*>`))
+		}
+		return res
+	}()
+
+	// mainContentRes locate the primary content container, in preference order.
+	mainContentRes = [][2]*regexp.Regexp{
+		{regexp.MustCompile(`(?i)<article\b[^>]*>`), regexp.MustCompile(`(?i)</article\s*>`)},
+		{regexp.MustCompile(`(?i)<main\b[^>]*>`), regexp.MustCompile(`(?i)</main\s*>`)},
+		{regexp.MustCompile(`(?i)<body\b[^>]*>`), regexp.MustCompile(`(?i)</body\s*>`)},
+	}
+)
+
+// htmlToText extracts the title and readable text of an HTML document.
+func htmlToText(doc string) (title, text string) {
+	if m := titleRe.FindStringSubmatch(doc); m != nil {
+		title = strings.Join(strings.Fields(html.UnescapeString(tagRe.ReplaceAllString(m[1], ""))), " ")
+	}
+
+	doc = commentRe.ReplaceAllString(doc, "")
+	for _, pair := range mainContentRes {
+		start := pair[0].FindStringIndex(doc)
+		ends := pair[1].FindAllStringIndex(doc, -1)
+		if start != nil && len(ends) > 0 && ends[len(ends)-1][0] > start[1] {
+			doc = doc[start[1]:ends[len(ends)-1][0]]
+			break
+		}
+	}
+	for _, re := range boilerplateRes {
+		doc = re.ReplaceAllString(doc, "")
+	}
+	doc = listRe.ReplaceAllString(doc, "\n- ")
+	doc = blockRe.ReplaceAllString(doc, "\n")
+	doc = html.UnescapeString(tagRe.ReplaceAllString(doc, ""))
+
+	// Collapse whitespace within lines and runs of blank lines.
+	var sb strings.Builder
+	blank := true
+	for _, line := range strings.Split(doc, "\n") {
+		line = strings.Join(strings.Fields(line), " ")
+		if line == "" || line == "-" {
+			if !blank {
+				sb.WriteByte('\n')
+				blank = true
+			}
+			continue
+		}
+		sb.WriteString(line)
+		sb.WriteByte('\n')
+		blank = false
+	}
+	return title, strings.TrimSpace(sb.String())
+}
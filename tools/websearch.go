@@ -0,0 +1,192 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/voocel/agentcore/schema"
+)
+
+// ErrNoSearchBackend is returned by WebSearchTool when no backend is configured.
+var ErrNoSearchBackend = errors.New("web search is not configured: no search backend")
+
+// SearchResult is one web search hit.
+type SearchResult struct {
+	Title   string `json:"title"`
+	URL     string `json:"url"`
+	Snippet string `json:"snippet,omitempty"`
+}
+
+// SearchBackend runs a web search and returns at most limit results.
+type SearchBackend interface {
+	Search(ctx context.Context, query string, limit int) ([]SearchResult, error)
+}
+
+// SearchFunc adapts a function to SearchBackend.
+type SearchFunc func(ctx context.Context, query string, limit int) ([]SearchResult, error)
+
+func (f SearchFunc) Search(ctx context.Context, query string, limit int) ([]SearchResult, error) {
+	return f(ctx, query, limit)
+}
+
+// WebSearchTool searches the web through a pluggable SearchBackend and
+// returns structured results (title, url, snippet).
+type WebSearchTool struct {
+	Backend    SearchBackend
+	MaxResults int           // default 5, upper bound for the model's max_results
+	Timeout    time.Duration // per search, default 30s
+}
+
+// NewWebSearch creates a web search tool backed by backend.
+//
+// Usage:
+//
+//	search := tools.NewWebSearch(tools.NewBraveSearch(os.Getenv("BRAVE_API_KEY")))
+func NewWebSearch(backend SearchBackend) *WebSearchTool {
+	return &WebSearchTool{Backend: backend, MaxResults: 5, Timeout: 30 * time.Second}
+}
+
+func (t *WebSearchTool) Name() string  { return "web_search" }
+func (t *WebSearchTool) Label() string { return "Web Search" }
+func (t *WebSearchTool) Description() string {
+	return fmt.Sprintf(
+		"Search the web and return up to %d results with title, url, and snippet. Use web_fetch to read a result in full.",
+		t.maxResults(),
+	)
+}
+func (t *WebSearchTool) Schema() map[string]any {
+	return schema.Object(
+		schema.Property("query", schema.String("Search query")).Required(),
+		schema.Property("max_results", schema.Int(fmt.Sprintf("Number of results (default and max: %d)", t.maxResults()))),
+	)
+}
+
+type webSearchArgs struct {
+	Query      string `json:"query"`
+	MaxResults int    `json:"max_results"`
+}
+
+func (t *WebSearchTool) maxResults() int {
+	if t.MaxResults <= 0 {
+		return 5
+	}
+	return t.MaxResults
+}
+
+func (t *WebSearchTool) Execute(ctx context.Context, args json.RawMessage) (json.RawMessage, error) {
+	var a webSearchArgs
+	if err := json.Unmarshal(args, &a); err != nil {
+		return nil, fmt.Errorf("invalid args: %w", err)
+	}
+	if t.Backend == nil {
+		return nil, ErrNoSearchBackend
+	}
+	query := strings.TrimSpace(a.Query)
+	if query == "" {
+		return nil, fmt.Errorf("query is required")
+	}
+	limit := t.maxResults()
+	if a.MaxResults > 0 && a.MaxResults < limit {
+		limit = a.MaxResults
+	}
+
+	timeout := t.Timeout
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	results, err := t.Backend.Search(ctx, query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("search: %w", err)
+	}
+	if len(results) > limit {
+		results = results[:limit]
+	}
+	if results == nil {
+		results = []SearchResult{}
+	}
+	return json.Marshal(map[string]any{"query": query, "results": results})
+}
+
+// BraveSearch is a SearchBackend for the Brave Search API
+// (https://api.search.brave.com).
+type BraveSearch struct {
+	APIKey  string
+	BaseURL string       // default https://api.search.brave.com/res/v1/web/search
+	Client  *http.Client // default http.DefaultClient
+}
+
+func NewBraveSearch(apiKey string) *BraveSearch {
+	return &BraveSearch{APIKey: apiKey}
+}
+
+func (b *BraveSearch) Search(ctx context.Context, query string, limit int) ([]SearchResult, error) {
+	if b.APIKey == "" {
+		return nil, fmt.Errorf("brave search: no API key configured")
+	}
+	endpoint := b.BaseURL
+	if endpoint == "" {
+		endpoint = "https://api.search.brave.com/res/v1/web/search"
+	}
+	params := url.Values{"q": {query}}
+	if limit > 0 {
+		params.Set("count", fmt.Sprint(min(limit, 20))) // API maximum
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint+"?"+params.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("X-Subscription-Token", b.APIKey)
+
+	client := b.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("brave search: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, fmt.Errorf("brave search: HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var parsed struct {
+		Web struct {
+			Results []struct {
+				Title       string `json:"title"`
+				URL         string `json:"url"`
+				Description string `json:"description"`
+			} `json:"results"`
+		} `json:"web"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&parsed); err != nil {
+		return nil, fmt.Errorf("brave search: decode response: %w", err)
+	}
+	results := make([]SearchResult, 0, len(parsed.Web.Results))
+	for _, r := range parsed.Web.Results {
+		results = append(results, SearchResult{
+			Title:   stripTags(r.Title),
+			URL:     r.URL,
+			Snippet: stripTags(r.Description), // snippets mark matches with <strong>
+		})
+	}
+	return results, nil
+}
+
+// stripTags removes inline markup and decodes entities from a short snippet.
+func stripTags(s string) string {
+	return strings.TrimSpace(html.UnescapeString(tagRe.ReplaceAllString(s, "")))
+}
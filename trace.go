@@ -0,0 +1,67 @@
+package agentcore
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+)
+
+// traceKey is the context key for the current Trace.
+type traceKey struct{}
+
+// Trace correlates the events of one top-level request across nested agent
+// loops (e.g. sub-agents started by SubAgentTool). Every loop run gets its own
+// RunID; the TraceID is inherited from the context, or generated for a
+// top-level run.
+type Trace struct {
+	TraceID     string
+	RunID       string
+	ParentRunID string // RunID of the enclosing loop, empty at top level
+}
+
+// WithTraceID sets the trace ID used by agent loops started with ctx, e.g. to
+// reuse an incoming request ID.
+func WithTraceID(ctx context.Context, traceID string) context.Context {
+	t := TraceFromContext(ctx)
+	t.TraceID = traceID
+	return context.WithValue(ctx, traceKey{}, t)
+}
+
+// TraceFromContext returns the trace of the loop run that ctx belongs to.
+// Tools, middleware, and permission callbacks receive such a context.
+// The zero Trace is returned outside a loop.
+func TraceFromContext(ctx context.Context) Trace {
+	t, _ := ctx.Value(traceKey{}).(Trace)
+	return t
+}
+
+// startTrace begins a new run under the trace in ctx, generating a trace ID
+// when there is none.
+func startTrace(ctx context.Context) (context.Context, Trace) {
+	parent := TraceFromContext(ctx)
+	t := Trace{TraceID: parent.TraceID, RunID: newTraceID(), ParentRunID: parent.RunID}
+	if t.TraceID == "" {
+		t.TraceID = newTraceID()
+	}
+	return context.WithValue(ctx, traceKey{}, t), t
+}
+
+func newTraceID() string {
+	var b [8]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// traceEvents stamps every event from in with t. Delivery stays non-blocking,
+// matching emit.
+func traceEvents(in <-chan Event, t Trace) <-chan Event {
+	out := make(chan Event, cap(in))
+	go func() {
+		defer close(out)
+		for ev := range in {
+			ev.TraceID, ev.RunID, ev.ParentRunID = t.TraceID, t.RunID, t.ParentRunID
+			emit(out, ev)
+		}
+	}()
+	return out
+}
@@ -345,6 +345,8 @@ type ToolResult struct {
 // Tool defines the minimal tool interface.
 // Timeout control goes through context.Context.
 // Tools can report execution progress via ReportToolProgress(ctx, partial).
+// A tool that fails after producing some output may return both; the result
+// then carries the error and the output as "partial_output".
 type Tool interface {
 	Name() string
 	Description() string
@@ -352,6 +354,13 @@ type Tool interface {
 	Execute(ctx context.Context, args json.RawMessage) (json.RawMessage, error)
 }
 
+// ToolOutputSchemaer is an optional interface for tools that declare the JSON
+// Schema of their result. Results are validated after execution and
+// mismatches are returned to the LLM as tool errors.
+type ToolOutputSchemaer interface {
+	OutputSchema() map[string]any
+}
+
 // ToolLabeler is an optional interface for tools to provide a human-readable label.
 type ToolLabeler interface {
 	Label() string
@@ -363,6 +372,11 @@ type ToolLabeler interface {
 // Receives context.Context to support I/O (e.g. TUI confirmation, remote policy).
 type PermissionFunc func(ctx context.Context, call ToolCall) error
 
+// ToolFilter reports whether the named tool may be offered and invoked in the
+// current call. It runs before every LLM call and every tool call, so it can
+// depend on state that changes between them.
+type ToolFilter func(ctx context.Context, name string) bool
+
 // ToolExecuteFunc is the function signature for tool execution.
 // Used as the "next" parameter in middleware chains.
 type ToolExecuteFunc func(ctx context.Context, args json.RawMessage) (json.RawMessage, error)
@@ -455,6 +469,14 @@ type LoopConfig struct {
 	// SessionID enables provider-level session caching (e.g. Anthropic prompt cache).
 	SessionID string
 
+	// Budget caps token and cost spend for this run. Nil = unlimited.
+	Budget *Budget
+
+	// Seed is passed to the model on every call. Models without a seed
+	// parameter (including the litellm adapters) ignore it.
+	// Nil leaves sampling at the provider default.
+	Seed *int
+
 	// Steering: called after each tool execution to check for user interruptions.
 	GetSteeringMessages func() []AgentMessage
 
@@ -464,6 +486,34 @@ type LoopConfig struct {
 	// Middlewares are applied around each tool execution (outermost first).
 	// Use for logging, timing, argument/result modification, etc.
 	Middlewares []ToolMiddleware
+
+	// InputGuards run over user messages before they enter the context.
+	// OutputGuards run over assistant text before it is emitted; while they
+	// are set, text is delivered once per block after the message completes.
+	InputGuards  []Guard
+	OutputGuards []Guard
+
+	// ProcessToolResult rewrites successful tool results before they are
+	// added to the conversation. Nil keeps results as returned.
+	ProcessToolResult ToolResultProcessor
+
+	// RenderSystemPrompt, when set, produces the system prompt before each LLM
+	// call. On error the AgentContext prompt is used and a warning is emitted.
+	RenderSystemPrompt func(tools []Tool) (string, error)
+
+	// LogRequest receives the full request and response of every LLM call,
+	// with API keys redacted. Nil disables it.
+	LogRequest RequestLogger
+
+	// ToolFilter narrows the tools offered to the model on each call. Calls to
+	// filtered-out tools are denied with a tool_denied event. Nil offers all tools.
+	ToolFilter ToolFilter
+
+	// RepairToolArgs fixes common JSON mistakes in tool call arguments
+	// (trailing commas, unquoted keys, single quotes, truncation) before
+	// validation. Arguments that cannot be repaired are rejected with the
+	// parse error and the tool's schema so the model can resend the call.
+	RepairToolArgs bool
 }
 
 // ---------------------------------------------------------------------------
@@ -493,9 +543,11 @@ type CallOption func(*CallConfig)
 // CallConfig holds per-call configuration resolved from CallOptions.
 type CallConfig struct {
 	ThinkingLevel  ThinkingLevel
-	ThinkingBudget int    // max thinking tokens, 0 = use provider default
-	APIKey         string // per-call API key override, empty = use model default
-	SessionID      string // provider session caching identifier
+	ThinkingBudget int            // max thinking tokens, 0 = use provider default
+	APIKey         string         // per-call API key override, empty = use model default
+	SessionID      string         // provider session caching identifier
+	ResponseSchema map[string]any // JSON Schema for native structured output, nil = free text
+	Seed           *int           // sampling seed for reproducible output, nil = provider default
 }
 
 // ResolveCallConfig applies options and returns the resolved config.
@@ -528,6 +580,18 @@ func WithCallSessionID(id string) CallOption {
 	return func(c *CallConfig) { c.SessionID = id }
 }
 
+// WithCallSeed sets the sampling seed for a single LLM call.
+// Models without a seed parameter, including the litellm adapters, ignore it.
+func WithCallSeed(seed int) CallOption {
+	return func(c *CallConfig) { c.Seed = &seed }
+}
+
+// WithResponseSchema requests a JSON response conforming to schema for a single
+// LLM call. Providers without a native JSON mode ignore it; see GenerateJSON.
+func WithResponseSchema(schema map[string]any) CallOption {
+	return func(c *CallConfig) { c.ResponseSchema = schema }
+}
+
 // ---------------------------------------------------------------------------
 // ChatModel Interface
 // ---------------------------------------------------------------------------
@@ -584,6 +648,26 @@ type StreamEvent struct {
 	Err          error      // for error events
 }
 
+// StreamMessage replays a complete message as a minimal stream: one delta per
+// text or thinking block, a toolcall_end per tool call, then done. Models
+// without native streaming use it to implement GenerateStream.
+func StreamMessage(msg Message) <-chan StreamEvent {
+	ch := make(chan StreamEvent, len(msg.Content)+1)
+	for i, b := range msg.Content {
+		switch b.Type {
+		case ContentText:
+			ch <- StreamEvent{Type: StreamEventTextDelta, ContentIndex: i, Delta: b.Text, Message: msg}
+		case ContentThinking:
+			ch <- StreamEvent{Type: StreamEventThinkingDelta, ContentIndex: i, Delta: b.Thinking, Message: msg}
+		case ContentToolCall:
+			ch <- StreamEvent{Type: StreamEventToolCallEnd, ContentIndex: i, Message: msg}
+		}
+	}
+	ch <- StreamEvent{Type: StreamEventDone, Message: msg, StopReason: msg.StopReason}
+	close(ch)
+	return ch
+}
+
 // ---------------------------------------------------------------------------
 // Queue Mode
 // ---------------------------------------------------------------------------
@@ -614,8 +698,10 @@ const (
 	EventToolExecStart  EventType = "tool_exec_start"
 	EventToolExecUpdate EventType = "tool_exec_update"
 	EventToolExecEnd    EventType = "tool_exec_end"
+	EventToolDenied     EventType = "tool_denied" // call rejected by the tool filter or permission check
 	EventRetry          EventType = "retry"
 	EventError          EventType = "error"
+	EventWarning        EventType = "warning" // non-fatal problem; the run continues
 )
 
 // Event is a lifecycle event emitted by the agent loop.
@@ -624,6 +710,7 @@ type Event struct {
 	Type        EventType
 	Message     AgentMessage    // for message_start/update/end, turn_end
 	Delta       string          // text delta for message_update
+	DeltaType   StreamEventType // kind of delta for message_update (text/thinking/toolcall)
 	ToolID      string          // for tool_exec_*
 	Tool        string          // tool name for tool_exec_*
 	ToolLabel   string          // human-readable tool label (from ToolLabeler)
@@ -631,9 +718,13 @@ type Event struct {
 	Result      json.RawMessage // tool result for tool_exec_end/update
 	IsError     bool            // tool error flag for tool_exec_end
 	ToolResults []ToolResult    // for turn_end: all tool results from this turn
-	Err         error           // for error events
+	Usage       *Usage          // for turn_end: cumulative usage of this run so far
+	Err         error           // for error and warning events
 	NewMessages []AgentMessage  // for agent_end: messages added during this loop
 	RetryInfo   *RetryInfo      // for retry events
+	TraceID     string          // shared by all runs of one top-level request
+	RunID       string          // unique per loop run
+	ParentRunID string          // RunID of the loop that started this one, if nested
 }
 
 // RetryInfo carries retry context for EventRetry events.